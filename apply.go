@@ -2,53 +2,86 @@ package migrations
 
 import (
 	"context"
-	"fmt"
 )
 
 // Apply applies all unapplied migrations, up to the target (if any), using the given provider, p.
-func Apply(ctx context.Context, cm []*Migration, p Provider, fr FileReader, targetName string) error {
-	am, err := p.GetAppliedMigrations(ctx)
+// If the target doesn't exist, a *NotFoundError is returned, before any migrations are applied.
+//
+// If the Transactional option is enabled and a migration fails, each migration
+// applied in this run is rolled back, in reverse order, and a *CompensationError is returned.
+//
+// If p implements Locker, the migration lock is held for the duration of the run.
+//
+// Progress is reported to the Observer given by the WithObserver option, if any.
+//
+// If the VerifyChecksums option is enabled, no migrations are applied
+// if any applied migrations have drifted; a *DriftError is returned.
+func Apply(ctx context.Context, cm []*Migration, p Provider, fr FileReader, targetName string, opts ...Option) error {
+	o, err := newOptions(opts)
 	if err != nil {
 		return err
 	}
 
-	for _, m := range cm {
-		fmt.Printf("Applying %s...\t", m.Name)
+	err = resolveTarget(cm, targetName)
+	if err != nil {
+		return err
+	}
 
-		if isApplied(am, m.Name) {
-			fmt.Printf("skipping.\n")
-			continue
-		}
+	ctx = o.context(ctx)
 
-		content, err := fr.Read(m.UpFile)
+	unlock, err := acquireLock(ctx, p, o.lockTimeout)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	am, err := p.GetAppliedMigrations(ctx)
+	if err != nil {
+		return err
+	}
+
+	if o.verifyChecksums {
+		drifts, err := verify(cm, am, fr)
 		if err != nil {
-			fmt.Printf("\nFailed to read migration file: %s.\n", m.UpFile)
 			return err
 		}
 
-		err = p.Apply(ctx, m.Name, content)
-		if err != nil {
-			fmt.Printf("\nFailed to apply migration %s.\n", m.Name)
-
-			return err
+		if len(drifts) > 0 {
+			return &DriftError{Drifts: drifts}
 		}
+	}
 
-		fmt.Printf("done.\n")
+	var applied []*Migration
 
-		if targetName != "" && targetName == m.Name {
+	return walk(cm, am, Up, targetName, o.steps, func(m *Migration, pending bool) error {
+		if !pending {
+			skipMigration(ctx, m, Up)
 			return nil
 		}
-	}
 
-	return nil
+		err := runMigration(ctx, m, Up, p, fr, false)
+		if err != nil {
+			return o.compensateApply(ctx, applied, p, fr, err)
+		}
+
+		applied = append(applied, m)
+
+		return nil
+	})
 }
 
-func isApplied(applied []*Migration, name string) bool {
-	for _, m := range applied {
-		if m.Name == name {
-			return true
-		}
+// compensateApply rolls back the migrations applied in the current run, if
+// running transactionally, otherwise err is returned as is.
+func (o *options) compensateApply(ctx context.Context, applied []*Migration, p Provider, fr FileReader, err error) error {
+	if !o.transactional {
+		return err
 	}
 
-	return false
+	return compensate(applied, err, func(m *Migration) error {
+		return runMigration(ctx, m, Down, p, fr, true)
+	})
+}
+
+func isApplied(applied []*Migration, name string) bool {
+	return findApplied(applied, name) != nil
 }
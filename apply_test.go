@@ -127,3 +127,194 @@ func TestApply_ApplyTargetMigration_SkipsFurtherMigrations(t *testing.T) {
 	err := migrations.Apply(testCtx, testMigrations, mockProvider, mockFileReader, "One")
 	assert.NoError(t, err)
 }
+
+func TestApply_GivenUnknownTarget_ReturnsNotFoundError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	testMigrations := []*migrations.Migration{
+		{Name: "AddUsersTable"},
+		{Name: "AddOrdersTable"},
+	}
+
+	mockProvider := mock.NewMockProvider(ctrl)
+
+	err := migrations.Apply(context.Background(), testMigrations, mockProvider, nil, "AddUserTable")
+	assert.EqualError(t, err, "migration AddUserTable does not exist, did you mean AddUsersTable?")
+
+	var notFound *migrations.NotFoundError
+	assert.True(t, errors.As(err, &notFound))
+	assert.Equal(t, "AddUsersTable", notFound.Suggestion)
+
+	err = migrations.Apply(context.Background(), testMigrations, mockProvider, nil, "Unrelated")
+	assert.EqualError(t, err, "migration Unrelated does not exist")
+}
+
+func TestApply_GivenAppliedTarget_SkipsFurtherMigrations(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	testCtx := context.Background()
+	testMigrations := []*migrations.Migration{
+		{Name: "One"},
+		{Name: "Two"},
+	}
+
+	mockProvider := mock.NewMockProvider(ctrl)
+	mockProvider.EXPECT().GetAppliedMigrations(testCtx).Return([]*migrations.Migration{{Name: "One"}}, nil)
+
+	err := migrations.Apply(testCtx, testMigrations, mockProvider, nil, "One")
+	assert.NoError(t, err)
+}
+
+func TestApply_GivenMigrationWithoutTransaction_TellsProvider(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	testCtx := context.Background()
+	noTransaction := false
+	testMigrations := []*migrations.Migration{
+		{Name: "One", UpFile: "one.sql"},
+		{Name: "Two", UpFile: "two.sql", Transaction: &noTransaction},
+	}
+
+	mockProvider := mock.NewMockProvider(ctrl)
+	mockProvider.EXPECT().GetAppliedMigrations(testCtx).Return([]*migrations.Migration{}, nil)
+	mockProvider.EXPECT().Apply(testCtx, "One", "").Return(nil)
+	mockProvider.EXPECT().Apply(gomock.Any(), "Two", "").DoAndReturn(func(ctx context.Context, name, content string) error {
+		assert.False(t, migrations.TransactionFromContext(ctx))
+		return nil
+	})
+
+	mockFileReader := mock.NewMockFileReader(ctrl)
+	mockFileReader.EXPECT().Read(gomock.Any()).Return("", nil).Times(2)
+
+	err := migrations.Apply(testCtx, testMigrations, mockProvider, mockFileReader, "")
+	assert.NoError(t, err)
+}
+
+func TestApply_TransactionalWhereMigrationFails_RollsBackAppliedMigrations(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	testCtx := context.Background()
+	testMigrations := []*migrations.Migration{
+		{Name: "One", UpFile: "One.up", DownFile: "One.down"},
+		{Name: "Two", UpFile: "Two.up", DownFile: "Two.down"},
+		{Name: "Three", UpFile: "Three.up", DownFile: "Three.down"},
+	}
+	testError := errors.New("an error occurred")
+
+	mockProvider := mock.NewMockProvider(ctrl)
+	mockFileReader := mock.NewMockFileReader(ctrl)
+
+	gomock.InOrder(
+		mockProvider.EXPECT().GetAppliedMigrations(testCtx).Return([]*migrations.Migration{}, nil),
+		mockFileReader.EXPECT().Read("One.up").Return("One Up", nil),
+		mockProvider.EXPECT().Apply(testCtx, "One", "One Up").Return(nil),
+		mockFileReader.EXPECT().Read("Two.up").Return("Two Up", nil),
+		mockProvider.EXPECT().Apply(testCtx, "Two", "Two Up").Return(nil),
+		mockFileReader.EXPECT().Read("Three.up").Return("Three Up", nil),
+		mockProvider.EXPECT().Apply(testCtx, "Three", "Three Up").Return(testError),
+		mockFileReader.EXPECT().Read("Two.down").Return("Two Down", nil),
+		mockProvider.EXPECT().Rollback(testCtx, "Two", "Two Down").Return(nil),
+		mockFileReader.EXPECT().Read("One.down").Return("One Down", nil),
+		mockProvider.EXPECT().Rollback(testCtx, "One", "One Down").Return(nil),
+	)
+
+	err := migrations.Apply(testCtx, testMigrations, mockProvider, mockFileReader, "", migrations.Transactional(true))
+	assert.True(t, errors.Is(err, testError))
+
+	var ce *migrations.CompensationError
+	assert.True(t, errors.As(err, &ce))
+	assert.Equal(t, []string{"Two", "One"}, ce.Compensated)
+	assert.Empty(t, ce.Failed)
+}
+
+func TestApply_TransactionalWhereCompensationFails_ReportsFailure(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	testCtx := context.Background()
+	testMigrations := []*migrations.Migration{
+		{Name: "One", UpFile: "One.up", DownFile: "One.down"},
+		{Name: "Two", UpFile: "Two.up", DownFile: "Two.down"},
+	}
+	testError := errors.New("an error occurred")
+
+	mockProvider := mock.NewMockProvider(ctrl)
+	mockProvider.EXPECT().GetAppliedMigrations(testCtx).Return([]*migrations.Migration{}, nil)
+	mockProvider.EXPECT().Apply(testCtx, "One", "One Up").Return(nil)
+
+	mockFileReader := mock.NewMockFileReader(ctrl)
+	mockFileReader.EXPECT().Read("One.up").Return("One Up", nil)
+	mockFileReader.EXPECT().Read("Two.up").Return("", testError)
+	mockFileReader.EXPECT().Read("One.down").Return("", os.ErrNotExist)
+
+	err := migrations.Apply(testCtx, testMigrations, mockProvider, mockFileReader, "", migrations.Transactional(true))
+
+	var ce *migrations.CompensationError
+	assert.True(t, errors.As(err, &ce))
+	assert.Equal(t, testError, ce.Err)
+	assert.Empty(t, ce.Compensated)
+	assert.Equal(t, 1, len(ce.Failed))
+	assert.Equal(t, "One", ce.Failed[0].Name)
+	assert.True(t, os.IsNotExist(ce.Failed[0].Err))
+}
+
+func TestApply_VerifyingChecksumsWhereMigrationHasDrifted_ReturnsDriftError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	testCtx := context.Background()
+	testMigrations := []*migrations.Migration{
+		{Name: "One", UpFile: "One.up"},
+		{Name: "Two", UpFile: "Two.up"},
+	}
+	testAppliedMigrations := []*migrations.Migration{
+		{Name: "One", Checksum: migrations.Checksum("One Up")},
+	}
+
+	mockProvider := mock.NewMockProvider(ctrl)
+	mockProvider.EXPECT().GetAppliedMigrations(testCtx).Return(testAppliedMigrations, nil)
+
+	mockFileReader := mock.NewMockFileReader(ctrl)
+	mockFileReader.EXPECT().Read("One.up").Return("One Up, changed", nil)
+
+	err := migrations.Apply(testCtx, testMigrations, mockProvider, mockFileReader, "", migrations.VerifyChecksums(true))
+
+	var de *migrations.DriftError
+	assert.True(t, errors.As(err, &de))
+	assert.Equal(t, 1, len(de.Drifts))
+	assert.Equal(t, "One", de.Drifts[0].Name)
+}
+
+func TestApply_GivenSteps_AppliesNumberOfPendingMigrations(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	testCtx := context.Background()
+	testMigrations := []*migrations.Migration{
+		{Name: "One"},
+		{Name: "Two", UpFile: "TwoUp"},
+		{Name: "Three", UpFile: "ThreeUp"},
+		{Name: "Four"},
+	}
+
+	mockProvider := mock.NewMockProvider(ctrl)
+	mockProvider.EXPECT().GetAppliedMigrations(testCtx).Return(testMigrations[:1], nil)
+	mockProvider.EXPECT().Apply(testCtx, "Two", "TwoContent").Return(nil)
+	mockProvider.EXPECT().Apply(testCtx, "Three", "ThreeContent").Return(nil)
+
+	mockFileReader := mock.NewMockFileReader(ctrl)
+	mockFileReader.EXPECT().Read("TwoUp").Return("TwoContent", nil)
+	mockFileReader.EXPECT().Read("ThreeUp").Return("ThreeContent", nil)
+
+	err := migrations.Apply(testCtx, testMigrations, mockProvider, mockFileReader, "", migrations.Steps(2))
+	assert.NoError(t, err)
+}
+
+func TestApply_GivenInvalidSteps_ReturnsError(t *testing.T) {
+	err := migrations.Apply(context.Background(), nil, nil, nil, "", migrations.Steps(0))
+	assert.EqualError(t, err, "the number of steps must be at least 1")
+}
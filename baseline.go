@@ -0,0 +1,62 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Baseline records each of the configured migrations, cm, up to and including the
+// target, as applied, without running them, using the given provider, p, which must
+// implement HistoryMarker. This is used to adopt migrations on an existing database,
+// whose schema is already up to date with the target, so that subsequent calls to
+// Apply only apply the migrations which follow it. Migrations which have already
+// been applied are skipped.
+//
+// If p implements Locker, the migration lock is held for the duration of the run.
+func Baseline(ctx context.Context, cm []*Migration, p Provider, fr FileReader, targetName string, opts ...Option) error {
+	hm, ok := p.(HistoryMarker)
+	if !ok {
+		return ErrHistoryNotSupported
+	}
+
+	if targetName == "" {
+		return fmt.Errorf("a target migration is required to baseline")
+	}
+
+	if err := resolveTarget(cm, targetName); err != nil {
+		return err
+	}
+
+	return withLock(ctx, p, opts, func(ctx context.Context, o *options, am []*Migration) error {
+		for _, m := range cm {
+			if isApplied(am, m.Name) {
+				ObserverFromContext(ctx).Observe(&Event{
+					Type:      EventMigrationSkipped,
+					Time:      time.Now(),
+					Direction: Up,
+					Migration: m.Name,
+					Baseline:  true,
+				})
+			} else {
+				err := markMigration(ctx, o, m, Up, func() error {
+					content, err := fr.Read(m.UpFile)
+					if err != nil {
+						return err
+					}
+
+					return hm.MarkApplied(ctx, m.Name, content, o.note)
+				})
+				if err != nil {
+					return err
+				}
+			}
+
+			if m.Name == targetName {
+				break
+			}
+		}
+
+		return nil
+	})
+}
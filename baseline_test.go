@@ -0,0 +1,127 @@
+package migrations_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/reecerussell/migrations"
+	"github.com/reecerussell/migrations/mock"
+)
+
+// markingProvider is a provider which implements migrations.HistoryMarker.
+type markingProvider struct {
+	*mock.MockProvider
+	*mock.MockHistoryMarker
+}
+
+func TestBaseline_GivenTarget_MarksMigrationsUpToTargetAsApplied(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	testCtx := context.Background()
+	cm := []*migrations.Migration{
+		{Name: "First", UpFile: "FirstUp"},
+		{Name: "Second", UpFile: "SecondUp"},
+		{Name: "Third", UpFile: "ThirdUp"},
+	}
+
+	mockProvider := mock.NewMockProvider(ctrl)
+	mockProvider.EXPECT().GetAppliedMigrations(testCtx).Return([]*migrations.Migration{}, nil)
+
+	mockMarker := mock.NewMockHistoryMarker(ctrl)
+	gomock.InOrder(
+		mockMarker.EXPECT().MarkApplied(testCtx, "First", "FirstContent", "").Return(nil),
+		mockMarker.EXPECT().MarkApplied(testCtx, "Second", "SecondContent", "").Return(nil),
+	)
+
+	mockFileReader := mock.NewMockFileReader(ctrl)
+	mockFileReader.EXPECT().Read("FirstUp").Return("FirstContent", nil)
+	mockFileReader.EXPECT().Read("SecondUp").Return("SecondContent", nil)
+
+	p := &markingProvider{mockProvider, mockMarker}
+	err := migrations.Baseline(testCtx, cm, p, mockFileReader, "Second")
+	assert.NoError(t, err)
+}
+
+func TestBaseline_HavingAppliedMigrations_SkipsAppliedMigrations(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	testCtx := context.Background()
+	cm := []*migrations.Migration{
+		{Name: "First", UpFile: "FirstUp"},
+		{Name: "Second", UpFile: "SecondUp"},
+	}
+
+	mockProvider := mock.NewMockProvider(ctrl)
+	mockProvider.EXPECT().GetAppliedMigrations(gomock.Any()).Return([]*migrations.Migration{{Name: "First"}}, nil)
+
+	mockMarker := mock.NewMockHistoryMarker(ctrl)
+	mockMarker.EXPECT().MarkApplied(gomock.Any(), "Second", "SecondContent", "").Return(nil)
+
+	mockFileReader := mock.NewMockFileReader(ctrl)
+	mockFileReader.EXPECT().Read("SecondUp").Return("SecondContent", nil)
+
+	obs := &recordingObserver{}
+	p := &markingProvider{mockProvider, mockMarker}
+	err := migrations.Baseline(testCtx, cm, p, mockFileReader, "Second", migrations.WithObserver(obs))
+	assert.NoError(t, err)
+
+	assert.Equal(t, []migrations.EventType{
+		migrations.EventMigrationSkipped,
+		migrations.EventMigrationMarked,
+	}, obs.types())
+	assert.Equal(t, "First", obs.events[0].Migration)
+	assert.True(t, obs.events[0].Baseline)
+}
+
+func TestBaseline_FailsToMarkMigration_ReturnsError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	testCtx := context.Background()
+	testError := errors.New("an error occurred")
+	cm := []*migrations.Migration{
+		{Name: "First", UpFile: "FirstUp"},
+		{Name: "Second", UpFile: "SecondUp"},
+	}
+
+	mockProvider := mock.NewMockProvider(ctrl)
+	mockProvider.EXPECT().GetAppliedMigrations(testCtx).Return([]*migrations.Migration{}, nil)
+
+	mockMarker := mock.NewMockHistoryMarker(ctrl)
+	mockMarker.EXPECT().MarkApplied(testCtx, "First", "FirstContent", "").Return(testError)
+
+	mockFileReader := mock.NewMockFileReader(ctrl)
+	mockFileReader.EXPECT().Read("FirstUp").Return("FirstContent", nil)
+
+	p := &markingProvider{mockProvider, mockMarker}
+	err := migrations.Baseline(testCtx, cm, p, mockFileReader, "Second")
+	assert.Equal(t, testError, err)
+}
+
+func TestBaseline_GivenUnknownTarget_ReturnsError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	p := &markingProvider{mock.NewMockProvider(ctrl), mock.NewMockHistoryMarker(ctrl)}
+	cm := []*migrations.Migration{{Name: "First"}}
+
+	err := migrations.Baseline(context.Background(), cm, p, nil, "Unknown")
+	assert.EqualError(t, err, "migration Unknown does not exist")
+
+	err = migrations.Baseline(context.Background(), cm, p, nil, "")
+	assert.EqualError(t, err, "a target migration is required to baseline")
+}
+
+func TestBaseline_GivenProviderWithoutHistoryMarker_ReturnsError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	err := migrations.Baseline(context.Background(), nil, mock.NewMockProvider(ctrl), nil, "First")
+	assert.Equal(t, migrations.ErrHistoryNotSupported, err)
+}
@@ -0,0 +1,81 @@
+package migrations
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// Checksum returns the checksum of a migration's content, as a hex encoded
+// SHA-256 hash. Providers should record it when applying a migration.
+func Checksum(content string) string {
+	hash := sha256.Sum256([]byte(content))
+
+	return hex.EncodeToString(hash[:])
+}
+
+// Drift describes an applied migration whose up file has
+// changed since the migration was applied.
+type Drift struct {
+	Name     string `json:"name"`
+	File     string `json:"file"`
+	Recorded string `json:"recorded"`
+	Actual   string `json:"actual"`
+}
+
+// DriftError is returned by Apply, when verifying checksums,
+// if any applied migrations have drifted.
+type DriftError struct {
+	Drifts []*Drift
+}
+
+func (e *DriftError) Error() string {
+	names := make([]string, len(e.Drifts))
+	for i, d := range e.Drifts {
+		names[i] = d.Name
+	}
+
+	return fmt.Sprintf("applied migrations have changed since they were applied: %s", strings.Join(names, ", "))
+}
+
+// Verify compares the checksum of each applied migration's up file against the
+// checksum recorded by the provider, p, returning any which differ. Applied
+// migrations without a recorded checksum, or missing from cm, are not verified.
+func Verify(ctx context.Context, cm []*Migration, p Provider, fr FileReader) ([]*Drift, error) {
+	am, err := p.GetAppliedMigrations(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return verify(cm, am, fr)
+}
+
+func verify(cm, am []*Migration, fr FileReader) ([]*Drift, error) {
+	drifts := []*Drift{}
+
+	for _, m := range cm {
+		a := findApplied(am, m.Name)
+		if a == nil || a.Checksum == "" {
+			continue
+		}
+
+		content, err := fr.Read(m.UpFile)
+		if err != nil {
+			return nil, err
+		}
+
+		checksum := Checksum(content)
+		if checksum != a.Checksum {
+			drifts = append(drifts, &Drift{
+				Name:     m.Name,
+				File:     m.UpFile,
+				Recorded: a.Checksum,
+				Actual:   checksum,
+			})
+		}
+	}
+
+	return drifts, nil
+}
@@ -0,0 +1,69 @@
+package migrations_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/reecerussell/migrations"
+	"github.com/reecerussell/migrations/mock"
+)
+
+func TestChecksum(t *testing.T) {
+	checksum := migrations.Checksum("Hello World")
+	assert.Equal(t, "a591a6d40bf420404a011733cfb7b190d62c65bf0bcda32b57b277d9ad9f146e", checksum)
+}
+
+func TestVerify_GivenChangedMigration_ReturnsDrift(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	testCtx := context.Background()
+	testMigrations := []*migrations.Migration{
+		{Name: "Unchanged", UpFile: "Unchanged.up"},
+		{Name: "Changed", UpFile: "Changed.up"},
+		{Name: "Legacy", UpFile: "Legacy.up"},
+		{Name: "Pending", UpFile: "Pending.up"},
+	}
+	testAppliedMigrations := []*migrations.Migration{
+		{Name: "Unchanged", Checksum: migrations.Checksum("Unchanged Up")},
+		{Name: "Changed", Checksum: migrations.Checksum("Changed Up")},
+		{Name: "Legacy"},
+	}
+
+	mockProvider := mock.NewMockProvider(ctrl)
+	mockProvider.EXPECT().GetAppliedMigrations(testCtx).Return(testAppliedMigrations, nil)
+
+	mockFileReader := mock.NewMockFileReader(ctrl)
+	mockFileReader.EXPECT().Read("Unchanged.up").Return("Unchanged Up", nil)
+	mockFileReader.EXPECT().Read("Changed.up").Return("Changed Up, again", nil)
+
+	drifts, err := migrations.Verify(testCtx, testMigrations, mockProvider, mockFileReader)
+	assert.NoError(t, err)
+	assert.Equal(t, []*migrations.Drift{
+		{
+			Name:     "Changed",
+			File:     "Changed.up",
+			Recorded: migrations.Checksum("Changed Up"),
+			Actual:   migrations.Checksum("Changed Up, again"),
+		},
+	}, drifts)
+}
+
+func TestVerify_FailsToGetAppliedMigrations_ReturnsError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	testCtx := context.Background()
+	testError := errors.New("an error occurred")
+
+	mockProvider := mock.NewMockProvider(ctrl)
+	mockProvider.EXPECT().GetAppliedMigrations(testCtx).Return(nil, testError)
+
+	drifts, err := migrations.Verify(testCtx, nil, mockProvider, nil)
+	assert.Nil(t, drifts)
+	assert.Equal(t, testError, err)
+}
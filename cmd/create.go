@@ -0,0 +1,22 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/reecerussell/migrations"
+)
+
+// create creates a new migration, with the given name, in the directory, dir,
+// adding it to the config file.
+func create(dir, configPath, name string, p migrations.Provider) error {
+	m, err := migrations.CreateMigration(dir, configPath, name, p)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Created migration %s:\n", m.Name)
+	fmt.Printf("\tup: %s\n", m.UpFile)
+	fmt.Printf("\tdown: %s\n", m.DownFile)
+
+	return nil
+}
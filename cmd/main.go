@@ -4,10 +4,13 @@ import (
 	"context"
 	"flag"
 	"fmt"
+	"io"
 	"os"
 	"os/signal"
 	"path"
+	"strings"
 	"syscall"
+	"time"
 
 	"github.com/reecerussell/migrations"
 	"github.com/reecerussell/migrations/providers"
@@ -15,12 +18,16 @@ import (
 	// providers
 	_ "github.com/reecerussell/migrations/providers/mssql"
 	_ "github.com/reecerussell/migrations/providers/mysql"
+	_ "github.com/reecerussell/migrations/providers/postgres"
+	_ "github.com/reecerussell/migrations/providers/sqlite"
 )
 
 const (
 	defaultFileContext   = "."
 	defaultConfigFile    = "migrations.yaml"
 	defaultTransactional = false
+	defaultOutput        = outputTable
+	defaultLogFormat     = logFormatText
 	version              = "v0.3.1"
 )
 
@@ -29,6 +36,16 @@ var (
 	configFile    string
 	target        string
 	transactional bool
+	output        string
+	dryRun        bool
+	verifyFirst   bool
+	lockTimeout   time.Duration
+	logFormat     string
+	yes           bool
+	steps         int
+	redoSteps     int
+	all           bool
+	note          string
 )
 
 func main() {
@@ -39,13 +56,71 @@ func main() {
 	upCommand.StringVar(&fileContext, "context", defaultFileContext, "The execution path of the migrations")
 	upCommand.StringVar(&configFile, "file", defaultConfigFile, "The name of the migrations config file")
 	upCommand.StringVar(&target, "target", "", "The migration to apply")
+	upCommand.IntVar(&steps, "steps", 0, "The number of pending migrations to apply")
 	upCommand.BoolVar(&transactional, "trans", defaultTransactional, "Determines wether if one migration in the list to apply fails, any applied in the list are rolled back.")
+	upCommand.BoolVar(&dryRun, "dry-run", false, "Prints the migrations which would be applied, without applying them")
+	upCommand.BoolVar(&verifyFirst, "verify", false, "Refuses to apply any migrations if applied migrations have changed since they were applied")
+	upCommand.DurationVar(&lockTimeout, "lock-timeout", migrations.DefaultLockTimeout, "The time to wait for another process to release the migration lock, or 0 to wait indefinitely")
+	upCommand.StringVar(&logFormat, "log-format", defaultLogFormat, "The format of the progress output, either text or json")
 
 	downCommand := flag.NewFlagSet("down", flag.ExitOnError)
 	downCommand.StringVar(&fileContext, "context", defaultFileContext, "The execution path of the migrations")
 	downCommand.StringVar(&configFile, "file", defaultConfigFile, "The name of the migrations config file")
 	downCommand.StringVar(&target, "target", "", "The migration to rollback")
+	downCommand.IntVar(&steps, "steps", 0, "The number of applied migrations to roll back")
+	downCommand.BoolVar(&all, "all", false, "Rolls back all applied migrations, if neither a target nor steps are given")
 	downCommand.BoolVar(&transactional, "trans", false, "Determines wether if one migration in the list to rollback fails, any rolled backed in the list are reapplied.")
+	downCommand.BoolVar(&dryRun, "dry-run", false, "Prints the migrations which would be rolled back, without rolling them back")
+	downCommand.DurationVar(&lockTimeout, "lock-timeout", migrations.DefaultLockTimeout, "The time to wait for another process to release the migration lock, or 0 to wait indefinitely")
+	downCommand.StringVar(&logFormat, "log-format", defaultLogFormat, "The format of the progress output, either text or json")
+
+	redoCommand := flag.NewFlagSet("redo", flag.ExitOnError)
+	redoCommand.StringVar(&fileContext, "context", defaultFileContext, "The execution path of the migrations")
+	redoCommand.StringVar(&configFile, "file", defaultConfigFile, "The name of the migrations config file")
+	redoCommand.IntVar(&redoSteps, "steps", 1, "The number of applied migrations to roll back and re-apply")
+	redoCommand.BoolVar(&transactional, "trans", defaultTransactional, "Determines wether if one migration fails to be rolled back, or re-applied, the others in the list are compensated.")
+	redoCommand.DurationVar(&lockTimeout, "lock-timeout", migrations.DefaultLockTimeout, "The time to wait for another process to release the migration lock, or 0 to wait indefinitely")
+	redoCommand.StringVar(&logFormat, "log-format", defaultLogFormat, "The format of the progress output, either text or json")
+
+	statusCommand := flag.NewFlagSet("status", flag.ExitOnError)
+	statusCommand.StringVar(&fileContext, "context", defaultFileContext, "The execution path of the migrations")
+	statusCommand.StringVar(&configFile, "file", defaultConfigFile, "The name of the migrations config file")
+	statusCommand.StringVar(&output, "output", defaultOutput, "The output format, either table or json")
+
+	verifyCommand := flag.NewFlagSet("verify", flag.ExitOnError)
+	verifyCommand.StringVar(&fileContext, "context", defaultFileContext, "The execution path of the migrations")
+	verifyCommand.StringVar(&configFile, "file", defaultConfigFile, "The name of the migrations config file")
+
+	baselineCommand := flag.NewFlagSet("baseline", flag.ExitOnError)
+	baselineCommand.StringVar(&fileContext, "context", defaultFileContext, "The execution path of the migrations")
+	baselineCommand.StringVar(&configFile, "file", defaultConfigFile, "The name of the migrations config file")
+	baselineCommand.StringVar(&target, "target", "", "The last migration to mark as applied")
+	baselineCommand.DurationVar(&lockTimeout, "lock-timeout", migrations.DefaultLockTimeout, "The time to wait for another process to release the migration lock, or 0 to wait indefinitely")
+	baselineCommand.StringVar(&logFormat, "log-format", defaultLogFormat, "The format of the progress output, either text or json")
+
+	markAppliedCommand := flag.NewFlagSet("mark-applied", flag.ExitOnError)
+	markAppliedCommand.StringVar(&fileContext, "context", defaultFileContext, "The execution path of the migrations")
+	markAppliedCommand.StringVar(&configFile, "file", defaultConfigFile, "The name of the migrations config file")
+	markAppliedCommand.DurationVar(&lockTimeout, "lock-timeout", migrations.DefaultLockTimeout, "The time to wait for another process to release the migration lock, or 0 to wait indefinitely")
+	markAppliedCommand.BoolVar(&yes, "yes", false, "Marks the migration without asking for confirmation")
+	markAppliedCommand.StringVar(&note, "note", "", "The reason for marking the migration, included in the audit note")
+	markAppliedCommand.StringVar(&logFormat, "log-format", defaultLogFormat, "The format of the progress output, either text or json")
+
+	markUnappliedCommand := flag.NewFlagSet("mark-unapplied", flag.ExitOnError)
+	markUnappliedCommand.StringVar(&fileContext, "context", defaultFileContext, "The execution path of the migrations")
+	markUnappliedCommand.StringVar(&configFile, "file", defaultConfigFile, "The name of the migrations config file")
+	markUnappliedCommand.DurationVar(&lockTimeout, "lock-timeout", migrations.DefaultLockTimeout, "The time to wait for another process to release the migration lock, or 0 to wait indefinitely")
+	markUnappliedCommand.BoolVar(&yes, "yes", false, "Marks the migration without asking for confirmation")
+	markUnappliedCommand.StringVar(&note, "note", "", "The reason for marking the migration, included in the audit note")
+	markUnappliedCommand.StringVar(&logFormat, "log-format", defaultLogFormat, "The format of the progress output, either text or json")
+
+	validateCommand := flag.NewFlagSet("validate", flag.ExitOnError)
+	validateCommand.StringVar(&fileContext, "context", defaultFileContext, "The execution path of the migrations")
+	validateCommand.StringVar(&configFile, "file", defaultConfigFile, "The name of the migrations config file")
+
+	createCommand := flag.NewFlagSet("create", flag.ExitOnError)
+	createCommand.StringVar(&fileContext, "context", defaultFileContext, "The execution path of the migrations")
+	createCommand.StringVar(&configFile, "file", defaultConfigFile, "The name of the migrations config file")
 
 	if len(os.Args) < 2 {
 		help()
@@ -55,9 +130,39 @@ func main() {
 	switch os.Args[1] {
 	case "up":
 		upCommand.Parse(os.Args[2:])
+		exitOnUsageError(checkTargeting(upCommand.Name(), target, steps, all))
 		break
 	case "down":
 		downCommand.Parse(os.Args[2:])
+		exitOnUsageError(checkTargeting(downCommand.Name(), target, steps, all))
+		break
+	case "redo":
+		redoCommand.Parse(os.Args[2:])
+		break
+	case "status":
+		statusCommand.Parse(os.Args[2:])
+		break
+	case "verify":
+		verifyCommand.Parse(os.Args[2:])
+		break
+	case "baseline":
+		baselineCommand.Parse(os.Args[2:])
+		if target == "" {
+			fmt.Printf("usage: %s baseline [arguments] -target <name>\n", os.Args[0])
+			os.Exit(2)
+		}
+		break
+	case "mark-applied":
+		exitOnUsageError(parseWithName(markAppliedCommand, os.Args[2:]))
+		break
+	case "mark-unapplied":
+		exitOnUsageError(parseWithName(markUnappliedCommand, os.Args[2:]))
+		break
+	case "validate":
+		validateCommand.Parse(os.Args[2:])
+		break
+	case "create":
+		exitOnUsageError(parseWithName(createCommand, os.Args[2:]))
 		break
 	case "version":
 		fmt.Printf("Migrations %s\n", version)
@@ -69,28 +174,123 @@ func main() {
 		break
 	}
 
-	fmt.Printf("Migrate transactionally: %v\n", transactional)
-	fmt.Printf("Using context: %s\n", fileContext)
+	// JSON output is intended to be machine readable, so
+	// shouldn't be mixed with any informational output.
+	quiet := output == outputJSON || logFormat == logFormatJSON
+	logf := func(format string, args ...interface{}) {
+		if !quiet {
+			fmt.Printf(format, args...)
+		}
+	}
+
+	if upCommand.Parsed() || downCommand.Parsed() || redoCommand.Parsed() {
+		logf("Migrate transactionally: %v\n", transactional)
+	}
+
+	logf("Using context: %s\n", fileContext)
 
 	configPath := path.Join(fileContext, configFile)
-	config, err := migrations.LoadConfigFromFile(configPath)
+	config, err := migrations.LoadConfig(fileContext, configFile)
 	if err != nil {
-		panic(err)
+		fmt.Printf("Could not load config file %s: %v\n", configPath, err)
+		os.Exit(1)
 	}
 
-	fmt.Printf("Using config file: %s\n", configFile)
-
-	p := providers.Get(config.Provider, config.Config)
-	fmt.Printf("Using provider: %s\n", config.Provider)
+	logf("Using config file: %s\n", configFile)
 
 	fr := migrations.NewFileReader(fileContext)
 
-	if upCommand.Parsed() {
-		err = migrations.Apply(ctx, config.Migrations, p, fr, target)
+	// Validation doesn't need a provider, as the config may not name a valid one.
+	if validateCommand.Parsed() {
+		err = validate(config, fr)
+		if err != nil {
+			fmt.Printf("An error occurred: %v\n", err)
+			os.Exit(1)
+		}
+
+		return
+	}
+
+	p, err := providers.Get(config.Provider, config.Config)
+	if err != nil {
+		fmt.Printf("Could not load provider %s: %v\n", config.Provider, err)
+		os.Exit(1)
+	}
+
+	logf("Using provider: %s\n", config.Provider)
+
+	printStatements, err := config.Config.GetBool("printStatements", false)
+	if err != nil {
+		fmt.Printf("An error occurred: %v\n", err)
+		os.Exit(1)
+	}
+
+	obs, err := observer(logFormat, printStatements)
+	if err != nil {
+		fmt.Printf("An error occurred: %v\n", err)
+		os.Exit(2)
 	}
 
-	if downCommand.Parsed() {
-		err = migrations.Rollback(ctx, config.Migrations, p, fr, target)
+	var stepOpts []migrations.Option
+	if steps > 0 {
+		stepOpts = append(stepOpts, migrations.Steps(steps))
+	}
+
+	switch {
+	case dryRun && upCommand.Parsed():
+		err = plan(ctx, config.Migrations, p, fr, migrations.Up, target, stepOpts...)
+	case dryRun && downCommand.Parsed():
+		err = plan(ctx, config.Migrations, p, fr, migrations.Down, target, stepOpts...)
+	case upCommand.Parsed():
+		err = migrations.Apply(ctx, config.Migrations, p, fr, target, append(stepOpts,
+			migrations.Transactional(transactional),
+			migrations.VerifyChecksums(verifyFirst),
+			migrations.LockTimeout(lockTimeout),
+			migrations.WithObserver(obs))...)
+	case downCommand.Parsed():
+		err = migrations.Rollback(ctx, config.Migrations, p, fr, target, append(stepOpts,
+			migrations.Transactional(transactional),
+			migrations.LockTimeout(lockTimeout),
+			migrations.WithObserver(obs))...)
+	case redoCommand.Parsed():
+		err = migrations.Redo(ctx, config.Migrations, p, fr, redoSteps,
+			migrations.Transactional(transactional),
+			migrations.LockTimeout(lockTimeout),
+			migrations.WithObserver(obs))
+	case statusCommand.Parsed():
+		err = status(ctx, config.Migrations, p, output)
+	case verifyCommand.Parsed():
+		err = verify(ctx, config.Migrations, p, fr)
+	case baselineCommand.Parsed():
+		err = migrations.Baseline(ctx, config.Migrations, p, fr, target,
+			migrations.LockTimeout(lockTimeout),
+			migrations.WithObserver(obs))
+	case markAppliedCommand.Parsed():
+		name := markAppliedCommand.Arg(0)
+		err = confirmMark(os.Stdin, name, "applied", yes)
+		if err == nil {
+			err = migrations.MarkApplied(ctx, config.Migrations, p, fr, name,
+				migrations.LockTimeout(lockTimeout),
+				migrations.WithObserver(obs),
+				migrations.Note(auditNote(config.Provider, note)))
+		}
+	case markUnappliedCommand.Parsed():
+		name := markUnappliedCommand.Arg(0)
+		err = confirmMark(os.Stdin, name, "unapplied", yes)
+		if err == nil {
+			err = migrations.MarkUnapplied(ctx, p, name,
+				migrations.LockTimeout(lockTimeout),
+				migrations.WithObserver(obs),
+				migrations.Note(auditNote(config.Provider, note)))
+		}
+	case createCommand.Parsed():
+		err = create(fileContext, configPath, createCommand.Arg(0), p)
+	}
+
+	// Providers which hold a connection pool implement io.Closer,
+	// which is closed here, as os.Exit doesn't run deferred calls.
+	if c, ok := p.(io.Closer); ok {
+		c.Close()
 	}
 
 	if err != nil {
@@ -99,6 +299,56 @@ func main() {
 	}
 }
 
+// exitOnUsageError prints err and exits, if the command's arguments were invalid.
+func exitOnUsageError(err error) {
+	if err != nil {
+		fmt.Printf("%v\n", err)
+		os.Exit(2)
+	}
+}
+
+// parseWithName parses the arguments of a command which takes a name, allowing
+// the name to be given either before or after the flags. An error is returned
+// unless exactly one name is given.
+func parseWithName(cmd *flag.FlagSet, args []string) error {
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		cmd.Parse(append(args[1:], args[0]))
+	} else {
+		cmd.Parse(args)
+	}
+
+	if cmd.NArg() != 1 {
+		return fmt.Errorf("usage: %s %s [arguments] <name>", os.Args[0], cmd.Name())
+	}
+
+	return nil
+}
+
+// checkTargeting validates the flags which determine how many migrations the up or
+// down command, with the given name, runs. As rolling back all migrations is
+// destructive, down requires -all to be given explicitly, if neither -target nor
+// -steps are.
+func checkTargeting(name, target string, steps int, all bool) error {
+	var problem string
+
+	switch {
+	case steps < 0:
+		problem = "-steps must be at least 1"
+	case target != "" && steps > 0:
+		problem = "-target and -steps cannot be used together"
+	case all && (target != "" || steps > 0):
+		problem = "-all cannot be used with -target or -steps"
+	case name == "down" && target == "" && steps == 0 && !all:
+		problem = "down requires -target or -steps, or -all to roll back all migrations"
+	}
+
+	if problem != "" {
+		return fmt.Errorf("%s\nusage: %s %s [arguments]", problem, os.Args[0], name)
+	}
+
+	return nil
+}
+
 func handleShutdown(cancel context.CancelFunc) {
 	stop := make(chan os.Signal, 1)
 	signal.Notify(stop, os.Interrupt, syscall.SIGTERM, syscall.SIGINT)
@@ -121,17 +371,123 @@ func help() {
 	fmt.Printf("\tcontext\tThe execution path of the migrations (default: %s)\n", defaultFileContext)
 	fmt.Printf("\tfile\tThe name of the migrations config file (default: %s)\n", defaultConfigFile)
 	fmt.Printf("\ttarget\tThe migration to apply. This will apply all migrations leading up to the target.\n")
+	fmt.Printf("\tsteps\tThe number of pending migrations to apply. Cannot be used with target.\n")
 	fmt.Printf("\ttrans\tDetermines wether to apply the migrations transactionally (default: %v)\n", defaultTransactional)
+	fmt.Printf("\tdry-run\tPrints the migrations, and their SQL, which would be applied, without applying them.\n")
+	fmt.Printf("\tverify\tRefuses to apply any migrations if applied migrations have changed since they were applied.\n")
+	fmt.Printf("\tlock-timeout\tThe time to wait for another process to release the migration lock, or 0 to wait indefinitely (default: %s)\n", migrations.DefaultLockTimeout)
+	fmt.Printf("\tlog-format\tThe format of the progress output, either %s or %s (default: %s)\n", logFormatText, logFormatJSON, defaultLogFormat)
 
 	fmt.Printf("\n")
 
 	// Down
 	fmt.Printf("down\n---\n")
 	fmt.Printf("description: Rolls back applied migrations.\n")
-	fmt.Printf("usage: %s down --context example --file migrations.yaml --trans\n", os.Args[0])
+	fmt.Printf("usage: %s down --context example --file migrations.yaml --steps 1 --trans\n", os.Args[0])
 	fmt.Printf("arguments:\n")
 	fmt.Printf("\tcontext: The execution path of the migrations (default: %s)\n", defaultFileContext)
 	fmt.Printf("\tfile: The name of the migrations config file (default: %s)\n", defaultConfigFile)
 	fmt.Printf("\ttarget: The migration to rollback. This will rollback all subsequent migrations, leading up to the target.\n")
+	fmt.Printf("\tsteps: The number of applied migrations to roll back, starting from the last in the config file. Cannot be used with target.\n")
+	fmt.Printf("\tall: Rolls back all applied migrations. Required if neither target nor steps are given.\n")
 	fmt.Printf("\ttrans: Determines wether to rollback the migrations transactionally (default: %v)\n", defaultTransactional)
+	fmt.Printf("\tdry-run: Prints the migrations, and their SQL, which would be rolled back, without rolling them back.\n")
+	fmt.Printf("\tlock-timeout: The time to wait for another process to release the migration lock, or 0 to wait indefinitely (default: %s)\n", migrations.DefaultLockTimeout)
+	fmt.Printf("\tlog-format: The format of the progress output, either %s or %s (default: %s)\n", logFormatText, logFormatJSON, defaultLogFormat)
+
+	fmt.Printf("\n")
+
+	// Redo
+	fmt.Printf("redo\n---\n")
+	fmt.Printf("description: Rolls back the last applied migrations, then re-applies them, reading their files afresh.\n")
+	fmt.Printf("usage: %s redo --context example --file migrations.yaml --steps 1\n", os.Args[0])
+	fmt.Printf("arguments:\n")
+	fmt.Printf("\tcontext: The execution path of the migrations (default: %s)\n", defaultFileContext)
+	fmt.Printf("\tfile: The name of the migrations config file (default: %s)\n", defaultConfigFile)
+	fmt.Printf("\tsteps: The number of applied migrations to roll back and re-apply (default: 1)\n")
+	fmt.Printf("\ttrans: Determines wether to roll back, and re-apply, the migrations transactionally (default: %v)\n", defaultTransactional)
+	fmt.Printf("\tlock-timeout: The time to wait for another process to release the migration lock, or 0 to wait indefinitely (default: %s)\n", migrations.DefaultLockTimeout)
+	fmt.Printf("\tlog-format: The format of the progress output, either %s or %s (default: %s)\n", logFormatText, logFormatJSON, defaultLogFormat)
+
+	fmt.Printf("\n")
+
+	// Status
+	fmt.Printf("status\n---\n")
+	fmt.Printf("description: Shows which migrations are applied, pending, or applied but missing from the config.\n")
+	fmt.Printf("usage: %s status --context example --file migrations.yaml --output table\n", os.Args[0])
+	fmt.Printf("arguments:\n")
+	fmt.Printf("\tcontext: The execution path of the migrations (default: %s)\n", defaultFileContext)
+	fmt.Printf("\tfile: The name of the migrations config file (default: %s)\n", defaultConfigFile)
+	fmt.Printf("\toutput: The output format, either %s or %s (default: %s)\n", outputTable, outputJSON, defaultOutput)
+
+	fmt.Printf("\n")
+
+	// Verify
+	fmt.Printf("verify\n---\n")
+	fmt.Printf("description: Verifies applied migrations have not changed since they were applied.\n")
+	fmt.Printf("usage: %s verify --context example --file migrations.yaml\n", os.Args[0])
+	fmt.Printf("arguments:\n")
+	fmt.Printf("\tcontext: The execution path of the migrations (default: %s)\n", defaultFileContext)
+	fmt.Printf("\tfile: The name of the migrations config file (default: %s)\n", defaultConfigFile)
+
+	fmt.Printf("\n")
+
+	// Baseline
+	fmt.Printf("baseline\n---\n")
+	fmt.Printf("description: Marks migrations, up to and including the target, as applied, without running them. Used to adopt migrations on an existing database.\n")
+	fmt.Printf("usage: %s baseline --context example --file migrations.yaml --target InitialCreation\n", os.Args[0])
+	fmt.Printf("arguments:\n")
+	fmt.Printf("\tcontext: The execution path of the migrations (default: %s)\n", defaultFileContext)
+	fmt.Printf("\tfile: The name of the migrations config file (default: %s)\n", defaultConfigFile)
+	fmt.Printf("\ttarget: The last migration to mark as applied. Required.\n")
+	fmt.Printf("\tlock-timeout: The time to wait for another process to release the migration lock, or 0 to wait indefinitely (default: %s)\n", migrations.DefaultLockTimeout)
+	fmt.Printf("\tlog-format: The format of the progress output, either %s or %s (default: %s)\n", logFormatText, logFormatJSON, defaultLogFormat)
+
+	fmt.Printf("\n")
+
+	// Mark applied
+	fmt.Printf("mark-applied\n---\n")
+	fmt.Printf("description: Records a migration as applied, without running it, to repair the migration history.\n")
+	fmt.Printf("usage: %s mark-applied --context example --file migrations.yaml --note \"applied by hand\" <name>\n", os.Args[0])
+	fmt.Printf("arguments:\n")
+	fmt.Printf("\tcontext: The execution path of the migrations (default: %s)\n", defaultFileContext)
+	fmt.Printf("\tfile: The name of the migrations config file (default: %s)\n", defaultConfigFile)
+	fmt.Printf("\tlock-timeout: The time to wait for another process to release the migration lock, or 0 to wait indefinitely (default: %s)\n", migrations.DefaultLockTimeout)
+	fmt.Printf("\tyes: Marks the migration without asking for confirmation.\n")
+	fmt.Printf("\tnote: The reason for marking the migration, included in the audit note.\n")
+	fmt.Printf("\tlog-format: The format of the progress output, either %s or %s (default: %s)\n", logFormatText, logFormatJSON, defaultLogFormat)
+
+	fmt.Printf("\n")
+
+	// Mark unapplied
+	fmt.Printf("mark-unapplied\n---\n")
+	fmt.Printf("description: Removes the record of an applied migration, without rolling it back, to repair the migration history.\n")
+	fmt.Printf("usage: %s mark-unapplied --context example --file migrations.yaml --note \"rolled back by hand\" <name>\n", os.Args[0])
+	fmt.Printf("arguments:\n")
+	fmt.Printf("\tcontext: The execution path of the migrations (default: %s)\n", defaultFileContext)
+	fmt.Printf("\tfile: The name of the migrations config file (default: %s)\n", defaultConfigFile)
+	fmt.Printf("\tlock-timeout: The time to wait for another process to release the migration lock, or 0 to wait indefinitely (default: %s)\n", migrations.DefaultLockTimeout)
+	fmt.Printf("\tyes: Marks the migration without asking for confirmation.\n")
+	fmt.Printf("\tnote: The reason for marking the migration, included in the audit note.\n")
+	fmt.Printf("\tlog-format: The format of the progress output, either %s or %s (default: %s)\n", logFormatText, logFormatJSON, defaultLogFormat)
+
+	fmt.Printf("\n")
+
+	// Validate
+	fmt.Printf("validate\n---\n")
+	fmt.Printf("description: Checks the config for problems, such as unknown providers, duplicate names and missing files, without connecting to the database.\n")
+	fmt.Printf("usage: %s validate --context example --file migrations.yaml\n", os.Args[0])
+	fmt.Printf("arguments:\n")
+	fmt.Printf("\tcontext: The execution path of the migrations (default: %s)\n", defaultFileContext)
+	fmt.Printf("\tfile: The name of the migrations config file (default: %s)\n", defaultConfigFile)
+
+	fmt.Printf("\n")
+
+	// Create
+	fmt.Printf("create\n---\n")
+	fmt.Printf("description: Creates the up and down files of a new migration, and adds it to the config file.\n")
+	fmt.Printf("usage: %s create --context example --file migrations.yaml <name>\n", os.Args[0])
+	fmt.Printf("arguments:\n")
+	fmt.Printf("\tcontext: The execution path of the migrations, where the files are created (default: %s)\n", defaultFileContext)
+	fmt.Printf("\tfile: The name of the migrations config file (default: %s)\n", defaultConfigFile)
 }
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"flag"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckTargeting(t *testing.T) {
+	tests := []struct {
+		name    string
+		command string
+		target  string
+		steps   int
+		all     bool
+		problem string
+	}{
+		{name: "Up Without Targeting", command: "up"},
+		{name: "Up Given Target", command: "up", target: "AddIndex"},
+		{name: "Up Given Steps", command: "up", steps: 1},
+		{name: "Down Given Target", command: "down", target: "AddIndex"},
+		{name: "Down Given Steps", command: "down", steps: 2},
+		{name: "Down Given All", command: "down", all: true},
+		{
+			name:    "Down Without Targeting",
+			command: "down",
+			problem: "down requires -target or -steps, or -all to roll back all migrations",
+		},
+		{
+			name:    "Negative Steps",
+			command: "up",
+			steps:   -1,
+			problem: "-steps must be at least 1",
+		},
+		{
+			name:    "Target And Steps",
+			command: "down",
+			target:  "AddIndex",
+			steps:   1,
+			problem: "-target and -steps cannot be used together",
+		},
+		{
+			name:    "All And Target",
+			command: "down",
+			target:  "AddIndex",
+			all:     true,
+			problem: "-all cannot be used with -target or -steps",
+		},
+		{
+			name:    "All And Steps",
+			command: "down",
+			steps:   1,
+			all:     true,
+			problem: "-all cannot be used with -target or -steps",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := checkTargeting(test.command, test.target, test.steps, test.all)
+			if test.problem == "" {
+				assert.NoError(t, err)
+				return
+			}
+
+			if assert.Error(t, err) {
+				assert.Contains(t, err.Error(), test.problem)
+			}
+		})
+	}
+}
+
+func TestParseWithName(t *testing.T) {
+	tests := []struct {
+		name         string
+		args         []string
+		expectedName string
+		expectedYes  bool
+		expectError  bool
+	}{
+		{name: "Name Only", args: []string{"AddIndex"}, expectedName: "AddIndex"},
+		{name: "Name Before Flags", args: []string{"AddIndex", "-yes"}, expectedName: "AddIndex", expectedYes: true},
+		{name: "Name After Flags", args: []string{"-yes", "AddIndex"}, expectedName: "AddIndex", expectedYes: true},
+		{name: "Without Name", args: []string{"-yes"}, expectError: true},
+		{name: "Without Arguments", args: []string{}, expectError: true},
+		{name: "Multiple Names", args: []string{"-yes", "AddIndex", "AddColumn"}, expectError: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var yes bool
+			cmd := flag.NewFlagSet("mark-applied", flag.ContinueOnError)
+			cmd.BoolVar(&yes, "yes", false, "")
+
+			err := parseWithName(cmd, test.args)
+			if test.expectError {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, test.expectedName, cmd.Arg(0))
+			assert.Equal(t, test.expectedYes, yes)
+		})
+	}
+}
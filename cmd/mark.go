@@ -0,0 +1,57 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/user"
+	"strings"
+)
+
+// errNotConfirmed is returned if the user doesn't confirm a change to the migration history.
+var errNotConfirmed = errors.New("the migration history was not modified")
+
+// confirmMark asks the user to confirm that the migration with the given name should
+// be marked with the given state, without running it, unless yes is true.
+func confirmMark(r io.Reader, name, state string, yes bool) error {
+	if yes {
+		return nil
+	}
+
+	fmt.Printf("Mark %s as %s, only modifying the migration history? [y/N]: ", name, state)
+
+	answer, _ := bufio.NewReader(r).ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return nil
+	default:
+		return errNotConfirmed
+	}
+}
+
+// auditNote returns the note recorded with the change to the migration
+// history, noting who made it, using which provider, and why. The time
+// of the change is recorded by the event the note is attached to.
+func auditNote(provider, note string) string {
+	if note == "" {
+		note = "none given"
+	}
+
+	return fmt.Sprintf("marked by %s, using the %s provider, only modifying the migration history. Reason: %s",
+		currentUser(), provider, note)
+}
+
+// currentUser returns the name of the user running the command.
+func currentUser() string {
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+
+	if name := os.Getenv("USER"); name != "" {
+		return name
+	}
+
+	return "unknown"
+}
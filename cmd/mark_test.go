@@ -0,0 +1,33 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfirmMark(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		yes      bool
+		expected error
+	}{
+		{name: "Given Yes Flag", input: "", yes: true},
+		{name: "Answered y", input: "y\n"},
+		{name: "Answered Yes", input: "Yes\n"},
+		{name: "Answered With Whitespace", input: "  yes  \n"},
+		{name: "Answered Without Newline", input: "y"},
+		{name: "Answered n", input: "n\n", expected: errNotConfirmed},
+		{name: "Answered Something Else", input: "sure\n", expected: errNotConfirmed},
+		{name: "Not Answered", input: "", expected: errNotConfirmed},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := confirmMark(strings.NewReader(test.input), "AddIndex", "applied", test.yes)
+			assert.Equal(t, test.expected, err)
+		})
+	}
+}
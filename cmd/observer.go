@@ -0,0 +1,34 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/reecerussell/migrations"
+)
+
+// Supported log formats.
+const (
+	logFormatText = "text"
+	logFormatJSON = "json"
+)
+
+// observer returns the Observer which writes the progress of
+// migrations to stdout, in the given format. If format is empty,
+// as the command doesn't support the -log-format flag, text is used.
+// Executed statements are only written as text if printStatements is
+// true, whereas JSON always includes them.
+func observer(format string, printStatements bool) (migrations.Observer, error) {
+	switch format {
+	case logFormatText, "":
+		if printStatements {
+			return migrations.NewVerboseTextObserver(os.Stdout), nil
+		}
+
+		return migrations.NewTextObserver(os.Stdout), nil
+	case logFormatJSON:
+		return migrations.NewJSONObserver(os.Stdout), nil
+	default:
+		return nil, fmt.Errorf("unsupported log format: %s", format)
+	}
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/reecerussell/migrations"
+)
+
+// plan prints the migrations which would be run in the direction, d,
+// along with their content, without running them.
+func plan(ctx context.Context, cm []*migrations.Migration, p migrations.Provider, fr migrations.FileReader, d migrations.Direction, target string, opts ...migrations.Option) error {
+	mp, err := migrations.Plan(ctx, cm, p, fr, d, target, opts...)
+	if err != nil {
+		return err
+	}
+
+	if len(mp.Migrations) == 0 {
+		fmt.Printf("Dry run: there are no migrations to run %s.\n", mp.Direction)
+		return nil
+	}
+
+	fmt.Printf("Dry run: the following migrations would be run %s, in order:\n", mp.Direction)
+
+	for i, m := range mp.Migrations {
+		fmt.Printf("\n%d. %s (%s)\n---\n%s\n", i+1, m.Name, m.File, strings.TrimSpace(m.Content))
+	}
+
+	return nil
+}
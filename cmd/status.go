@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/reecerussell/migrations"
+)
+
+// Supported output formats.
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+)
+
+// status prints the state of each migration, in the given output format.
+func status(ctx context.Context, cm []*migrations.Migration, p migrations.Provider, format string) error {
+	statuses, err := migrations.Status(ctx, cm, p)
+	if err != nil {
+		return err
+	}
+
+	switch format {
+	case outputJSON:
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+
+		return enc.Encode(statuses)
+	case outputTable:
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintf(w, "NAME\tSTATE\tDATE APPLIED\n")
+
+		for _, s := range statuses {
+			dateApplied := "-"
+			if s.DateApplied != nil {
+				dateApplied = s.DateApplied.Format(time.RFC3339)
+			}
+
+			fmt.Fprintf(w, "%s\t%s\t%s\n", s.Name, stateLabel(s.State), dateApplied)
+		}
+
+		return w.Flush()
+	default:
+		return fmt.Errorf("unsupported output format '%s'", format)
+	}
+}
+
+func stateLabel(state migrations.MigrationState) string {
+	if state == migrations.StateUnknown {
+		return "applied but missing from config"
+	}
+
+	return string(state)
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/reecerussell/migrations"
+	"github.com/reecerussell/migrations/providers"
+)
+
+// validate prints each problem with the config, returning an error if there are any.
+func validate(config *migrations.Config, fr migrations.FileReader) error {
+	err := config.Validate(fr, providers.List())
+
+	var validationErr *migrations.ValidationError
+	if !errors.As(err, &validationErr) {
+		if err == nil {
+			fmt.Printf("The config is valid.\n")
+		}
+
+		return err
+	}
+
+	for _, problem := range validationErr.Problems {
+		fmt.Printf("%s\n", problem)
+	}
+
+	return fmt.Errorf("the config has %d problem(s)", len(validationErr.Problems))
+}
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/reecerussell/migrations"
+)
+
+// verify prints each applied migration which has changed since it was
+// applied, returning an error if there are any.
+func verify(ctx context.Context, cm []*migrations.Migration, p migrations.Provider, fr migrations.FileReader) error {
+	drifts, err := migrations.Verify(ctx, cm, p, fr)
+	if err != nil {
+		return err
+	}
+
+	if len(drifts) == 0 {
+		fmt.Printf("No applied migrations have changed.\n")
+		return nil
+	}
+
+	for _, d := range drifts {
+		fmt.Printf("%s: %s has changed (recorded: %s, actual: %s)\n", d.Name, d.File, d.Recorded, d.Actual)
+	}
+
+	return &migrations.DriftError{Drifts: drifts}
+}
@@ -0,0 +1,74 @@
+package migrations
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CompensationError is returned by Apply and Rollback, when running transactionally,
+// if a migration fails. It holds the original error, as well as a report of the
+// migrations which were compensated, and those which failed to be compensated.
+type CompensationError struct {
+	// Err is the error which caused the batch to fail.
+	Err error
+
+	// Compensated holds the names of the migrations which were
+	// successfully compensated, in the order they were compensated.
+	Compensated []string
+
+	// Failed holds the migrations which could not be compensated.
+	Failed []*CompensationFailure
+}
+
+// CompensationFailure records a migration which could not be compensated.
+type CompensationFailure struct {
+	Name string
+	Err  error
+}
+
+func (e *CompensationError) Error() string {
+	var sb strings.Builder
+	sb.WriteString(e.Err.Error())
+
+	if len(e.Compensated) > 0 {
+		fmt.Fprintf(&sb, "; compensated: %s", strings.Join(e.Compensated, ", "))
+	} else {
+		sb.WriteString("; nothing was compensated")
+	}
+
+	if len(e.Failed) > 0 {
+		failures := make([]string, len(e.Failed))
+		for i, f := range e.Failed {
+			failures[i] = fmt.Sprintf("%s (%v)", f.Name, f.Err)
+		}
+
+		fmt.Fprintf(&sb, "; failed to compensate: %s", strings.Join(failures, ", "))
+	}
+
+	return sb.String()
+}
+
+// Unwrap returns the error which caused the batch to fail.
+func (e *CompensationError) Unwrap() error {
+	return e.Err
+}
+
+// compensate calls fn for each of the given migrations, in reverse order,
+// recording the outcome of each in a CompensationError, caused by err.
+func compensate(ms []*Migration, err error, fn func(m *Migration) error) error {
+	ce := &CompensationError{Err: err}
+
+	for i := len(ms) - 1; i >= 0; i-- {
+		m := ms[i]
+
+		err := fn(m)
+		if err != nil {
+			ce.Failed = append(ce.Failed, &CompensationFailure{Name: m.Name, Err: err})
+			continue
+		}
+
+		ce.Compensated = append(ce.Compensated, m.Name)
+	}
+
+	return ce
+}
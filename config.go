@@ -1,8 +1,14 @@
 package migrations
 
 import (
+	"errors"
 	"fmt"
+	"io/fs"
 	"io/ioutil"
+	"path"
+	"strconv"
+	"strings"
+	"time"
 
 	"gopkg.in/yaml.v2"
 )
@@ -12,24 +18,71 @@ type Config struct {
 	Provider   string       `yaml:"provider"`
 	Config     ConfigMap    `yaml:"config"`
 	Migrations []*Migration `yaml:"migrations"`
+
+	// Discover determines whether Migrations are discovered from the
+	// files in Directory, rather than being listed in the config file.
+	Discover bool `yaml:"discover"`
+
+	// Directory is the directory, relative to the config file,
+	// migrations are discovered in. Defaults to the config file's directory.
+	Directory string `yaml:"directory"`
 }
 
 // LoadConfigFromFile returns an instance of Config, populated
-// with data from a YAML file.
+// with data from a YAML file. If discovery is enabled, the migrations
+// are discovered using DiscoverMigrations, and their files are
+// relative to the config file's directory.
 func LoadConfigFromFile(filename string) (*Config, error) {
-	bytes, err := ioutil.ReadFile(filename)
+	return LoadConfig(path.Dir(filename), path.Base(filename))
+}
+
+// LoadConfig returns an instance of Config, populated with data from
+// the YAML file, filename, relative to the file context, fileContext.
+// If discovery is enabled, the discovered migrations' files are also
+// relative to fileContext, so they can be read with NewFileReader(fileContext).
+func LoadConfig(fileContext, filename string) (*Config, error) {
+	bytes, err := ioutil.ReadFile(path.Join(fileContext, filename))
 	if err != nil {
-		fmt.Printf("Could not read config file %s\n", filename)
+		return nil, err
+	}
 
+	return parseConfig(bytes, func(dir string) ([]*Migration, error) {
+		return DiscoverMigrations(fileContext, path.Join(path.Dir(filename), dir))
+	})
+}
+
+// LoadConfigFromFS returns an instance of Config, populated with data from
+// a YAML file, read from fsys, such as an embed.FS. If discovery is enabled,
+// the migrations are discovered in fsys, using DiscoverMigrationsFS.
+func LoadConfigFromFS(fsys fs.FS, filename string) (*Config, error) {
+	bytes, err := fs.ReadFile(fsys, path.Clean(filename))
+	if err != nil {
 		return nil, err
 	}
 
+	return parseConfig(bytes, func(dir string) ([]*Migration, error) {
+		return DiscoverMigrationsFS(fsys, path.Join(path.Dir(filename), dir))
+	})
+}
+
+// parseConfig parses the YAML config, using discover to discover
+// the migrations in the given directory, if discovery is enabled.
+func parseConfig(bytes []byte, discover func(dir string) ([]*Migration, error)) (*Config, error) {
 	var config Config
-	err = yaml.Unmarshal(bytes, &config)
+	err := yaml.Unmarshal(bytes, &config)
 	if err != nil {
-		fmt.Printf("Config file does not contain valid YAML.\n")
+		return nil, fmt.Errorf("config file does not contain valid YAML: %w", err)
+	}
 
-		return nil, err
+	if config.Discover {
+		if len(config.Migrations) > 0 {
+			return nil, errors.New("migrations cannot be listed in the config file when discovery is enabled")
+		}
+
+		config.Migrations, err = discover(config.Directory)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	return &config, nil
@@ -37,22 +90,249 @@ func LoadConfigFromFile(filename string) (*Config, error) {
 
 // ConfigMap represents a map[string]interface{}, providing
 // helper functions to access variables.
+//
+// Each accessor returns false if the key isn't set, or its value can't be
+// coerced to the type, while the Get variants return the given default if
+// the key isn't set, or a *ConfigError if its value can't be coerced.
 type ConfigMap map[string]interface{}
 
+// ConfigError is returned if a value in a ConfigMap is not of the expected type.
+type ConfigError struct {
+	Key   string
+	Type  string
+	Value interface{}
+}
+
+func (e *ConfigError) Error() string {
+	return fmt.Sprintf("config property %s must be %s, but is %#v", e.Key, e.Type, e.Value)
+}
+
+// String returns the value of key, if it's a string.
 func (m ConfigMap) String(key string) (string, bool) {
-	if m == nil {
-		return "", false
-	}
+	s, ok := m[key].(string)
 
+	return s, ok
+}
+
+// GetString returns the value of key, if it's a string, or def if it isn't set.
+func (m ConfigMap) GetString(key, def string) (string, error) {
 	v, ok := m[key]
 	if !ok {
-		return "", false
+		return def, nil
 	}
 
 	s, ok := v.(string)
 	if !ok {
-		return "", false
+		return def, &ConfigError{Key: key, Type: "a string", Value: v}
+	}
+
+	return s, nil
+}
+
+// Int returns the value of key as an int, if it's a whole number, or a string
+// of one, such as "5".
+func (m ConfigMap) Int(key string) (int, bool) {
+	return toInt(m[key])
+}
+
+// GetInt returns the value of key as an int, as with Int, or def if it isn't set.
+func (m ConfigMap) GetInt(key string, def int) (int, error) {
+	v, ok := m[key]
+	if !ok {
+		return def, nil
+	}
+
+	i, ok := toInt(v)
+	if !ok {
+		return def, &ConfigError{Key: key, Type: "a whole number", Value: v}
+	}
+
+	return i, nil
+}
+
+// Bool returns the value of key as a bool, if it's a bool, or a string YAML
+// would treat as one, such as "true", "no" or "on", for values which were quoted.
+func (m ConfigMap) Bool(key string) (bool, bool) {
+	return toBool(m[key])
+}
+
+// GetBool returns the value of key as a bool, as with Bool, or def if it isn't set.
+func (m ConfigMap) GetBool(key string, def bool) (bool, error) {
+	v, ok := m[key]
+	if !ok {
+		return def, nil
+	}
+
+	b, ok := toBool(v)
+	if !ok {
+		return def, &ConfigError{Key: key, Type: "a bool", Value: v}
+	}
+
+	return b, nil
+}
+
+// Duration returns the value of key as a time.Duration, if it's a string
+// accepted by time.ParseDuration, such as "1m30s", or a whole number of seconds.
+func (m ConfigMap) Duration(key string) (time.Duration, bool) {
+	return toDuration(m[key])
+}
+
+// GetDuration returns the value of key as a time.Duration, as
+// with Duration, or def if it isn't set.
+func (m ConfigMap) GetDuration(key string, def time.Duration) (time.Duration, error) {
+	v, ok := m[key]
+	if !ok {
+		return def, nil
+	}
+
+	d, ok := toDuration(v)
+	if !ok {
+		return def, &ConfigError{Key: key, Type: "a duration", Value: v}
+	}
+
+	return d, nil
+}
+
+// StringSlice returns the value of key as a []string, if it's a list of
+// strings, numbers or bools, which are formatted as strings, or a single string.
+func (m ConfigMap) StringSlice(key string) ([]string, bool) {
+	return toStringSlice(m[key])
+}
+
+// GetStringSlice returns the value of key as a []string, as
+// with StringSlice, or def if it isn't set.
+func (m ConfigMap) GetStringSlice(key string, def []string) ([]string, error) {
+	v, ok := m[key]
+	if !ok {
+		return def, nil
+	}
+
+	ss, ok := toStringSlice(v)
+	if !ok {
+		return def, &ConfigError{Key: key, Type: "a list of strings", Value: v}
+	}
+
+	return ss, nil
+}
+
+// Map returns the value of key as a ConfigMap, if it's a map with string keys,
+// such as a nested YAML mapping, so that its values can be accessed in the same way.
+func (m ConfigMap) Map(key string) (ConfigMap, bool) {
+	return toMap(m[key])
+}
+
+// GetMap returns the value of key as a ConfigMap, as with
+// Map, or def if it isn't set.
+func (m ConfigMap) GetMap(key string, def ConfigMap) (ConfigMap, error) {
+	v, ok := m[key]
+	if !ok {
+		return def, nil
+	}
+
+	cm, ok := toMap(v)
+	if !ok {
+		return def, &ConfigError{Key: key, Type: "a map", Value: v}
+	}
+
+	return cm, nil
+}
+
+func toInt(v interface{}) (int, bool) {
+	switch v := v.(type) {
+	case int:
+		return v, true
+	case int64:
+		return int(v), true
+	case uint64:
+		return int(v), true
+	case float64:
+		if v != float64(int(v)) {
+			return 0, false
+		}
+
+		return int(v), true
+	case string:
+		// Numbers which were quoted are strings, as with bools.
+		i, err := strconv.Atoi(v)
+
+		return i, err == nil
+	default:
+		return 0, false
 	}
+}
+
+func toBool(v interface{}) (bool, bool) {
+	switch v := v.(type) {
+	case bool:
+		return v, true
+	case string:
+		// These are the spellings YAML 1.1 treats as bools, when unquoted.
+		switch strings.ToLower(v) {
+		case "true", "yes", "y", "on":
+			return true, true
+		case "false", "no", "n", "off":
+			return false, true
+		}
+	}
+
+	return false, false
+}
+
+func toDuration(v interface{}) (time.Duration, bool) {
+	if s, ok := v.(string); ok {
+		d, err := time.ParseDuration(s)
 
-	return s, true
+		return d, err == nil
+	}
+
+	seconds, ok := toInt(v)
+
+	return time.Duration(seconds) * time.Second, ok
+}
+
+func toStringSlice(v interface{}) ([]string, bool) {
+	switch v := v.(type) {
+	case string:
+		return []string{v}, true
+	case []string:
+		return v, true
+	case []interface{}:
+		ss := make([]string, len(v))
+		for i, e := range v {
+			switch e.(type) {
+			case string, bool, int, int64, uint64, float64:
+				ss[i] = fmt.Sprint(e)
+			default:
+				return nil, false
+			}
+		}
+
+		return ss, true
+	default:
+		return nil, false
+	}
+}
+
+func toMap(v interface{}) (ConfigMap, bool) {
+	switch v := v.(type) {
+	case ConfigMap:
+		return v, true
+	case map[string]interface{}:
+		return ConfigMap(v), true
+	case map[interface{}]interface{}:
+		// yaml.v2 decodes nested mappings with interface{} keys.
+		cm := make(ConfigMap, len(v))
+		for k, e := range v {
+			s, ok := k.(string)
+			if !ok {
+				return nil, false
+			}
+
+			cm[s] = e
+		}
+
+		return cm, true
+	default:
+		return nil, false
+	}
 }
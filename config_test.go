@@ -1,10 +1,16 @@
 package migrations
 
 import (
+	"errors"
+	"io/ioutil"
 	"os"
+	"path"
 	"testing"
+	"testing/fstest"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v2"
 )
 
 func TestLoadConfigFromFile_GivenValidFilename_ReturnsConfig(t *testing.T) {
@@ -102,3 +108,261 @@ func TestConfigMapGet_ValueIsNotString_ReturnsFalse(t *testing.T) {
 	assert.Equal(t, "", v)
 	assert.False(t, ok)
 }
+
+func TestConfigMapInt_HavingIntValue_ReturnsValue(t *testing.T) {
+	conf := ConfigMap{
+		"MyInt": 10,
+	}
+	v, ok := conf.Int("MyInt")
+	assert.Equal(t, 10, v)
+	assert.True(t, ok)
+}
+
+func TestConfigMapInt_HavingWholeFloatValue_ReturnsValue(t *testing.T) {
+	conf := ConfigMap{
+		"MyInt": float64(10),
+	}
+	v, ok := conf.Int("MyInt")
+	assert.Equal(t, 10, v)
+	assert.True(t, ok)
+}
+
+func TestConfigMapInt_HavingFractionalValue_ReturnsFalse(t *testing.T) {
+	conf := ConfigMap{
+		"MyInt": 1.5,
+	}
+	v, ok := conf.Int("MyInt")
+	assert.Equal(t, 0, v)
+	assert.False(t, ok)
+}
+
+func TestConfigMapInt_HavingNumericString_ReturnsValue(t *testing.T) {
+	conf := ConfigMap{
+		"Quoted":  "5",
+		"Invalid": "x",
+	}
+
+	v, ok := conf.Int("Quoted")
+	assert.Equal(t, 5, v)
+	assert.True(t, ok)
+
+	v, ok = conf.Int("Invalid")
+	assert.Equal(t, 0, v)
+	assert.False(t, ok)
+}
+
+func TestConfigMapBool_HavingBoolOrYAMLBoolString_ReturnsValue(t *testing.T) {
+	conf := ConfigMap{
+		"Bool":       true,
+		"QuotedTrue": "true",
+		"QuotedNo":   "No",
+		"Invalid":    "maybe",
+	}
+
+	v, ok := conf.Bool("Bool")
+	assert.True(t, v)
+	assert.True(t, ok)
+
+	v, ok = conf.Bool("QuotedTrue")
+	assert.True(t, v)
+	assert.True(t, ok)
+
+	v, ok = conf.Bool("QuotedNo")
+	assert.False(t, v)
+	assert.True(t, ok)
+
+	_, ok = conf.Bool("Invalid")
+	assert.False(t, ok)
+
+	_, ok = conf.Bool("Missing")
+	assert.False(t, ok)
+}
+
+func TestConfigMapDuration_HavingDurationStringOrSeconds_ReturnsValue(t *testing.T) {
+	conf := ConfigMap{
+		"String":  "1m30s",
+		"Seconds": 10,
+		"Invalid": "soon",
+	}
+
+	v, ok := conf.Duration("String")
+	assert.Equal(t, 90*time.Second, v)
+	assert.True(t, ok)
+
+	v, ok = conf.Duration("Seconds")
+	assert.Equal(t, 10*time.Second, v)
+	assert.True(t, ok)
+
+	_, ok = conf.Duration("Invalid")
+	assert.False(t, ok)
+}
+
+func TestConfigMapStringSlice_HavingListOrString_ReturnsValue(t *testing.T) {
+	conf := ConfigMap{
+		"List":    []interface{}{"a", 1, true},
+		"String":  "a",
+		"Invalid": []interface{}{"a", []interface{}{"b"}},
+	}
+
+	v, ok := conf.StringSlice("List")
+	assert.Equal(t, []string{"a", "1", "true"}, v)
+	assert.True(t, ok)
+
+	v, ok = conf.StringSlice("String")
+	assert.Equal(t, []string{"a"}, v)
+	assert.True(t, ok)
+
+	_, ok = conf.StringSlice("Invalid")
+	assert.False(t, ok)
+}
+
+func TestConfigMapMap_HavingNestedYAMLMapping_ReturnsConfigMap(t *testing.T) {
+	var config Config
+	err := yaml.Unmarshal([]byte("config:\n  options:\n    timeout: 5s\n    enabled: yes"), &config)
+	assert.NoError(t, err)
+
+	options, ok := config.Config.Map("options")
+	assert.True(t, ok)
+
+	timeout, _ := options.Duration("timeout")
+	assert.Equal(t, 5*time.Second, timeout)
+
+	enabled, _ := options.Bool("enabled")
+	assert.True(t, enabled)
+
+	_, ok = config.Config.Map("missing")
+	assert.False(t, ok)
+}
+
+func TestConfigMapGet_GivenMissingKey_ReturnsDefault(t *testing.T) {
+	var conf ConfigMap
+
+	s, err := conf.GetString("Key", "default")
+	assert.Equal(t, "default", s)
+	assert.NoError(t, err)
+
+	i, err := conf.GetInt("Key", 5)
+	assert.Equal(t, 5, i)
+	assert.NoError(t, err)
+
+	b, err := conf.GetBool("Key", true)
+	assert.True(t, b)
+	assert.NoError(t, err)
+
+	d, err := conf.GetDuration("Key", time.Second)
+	assert.Equal(t, time.Second, d)
+	assert.NoError(t, err)
+
+	ss, err := conf.GetStringSlice("Key", []string{"a"})
+	assert.Equal(t, []string{"a"}, ss)
+	assert.NoError(t, err)
+
+	m, err := conf.GetMap("Key", nil)
+	assert.Nil(t, m)
+	assert.NoError(t, err)
+}
+
+func TestConfigMapGet_GivenInvalidValue_ReturnsConfigError(t *testing.T) {
+	conf := ConfigMap{
+		"Key": "five",
+	}
+
+	_, err := conf.GetInt("Key", 0)
+	assert.EqualError(t, err, `config property Key must be a whole number, but is "five"`)
+
+	var configErr *ConfigError
+	assert.True(t, errors.As(err, &configErr))
+	assert.Equal(t, "Key", configErr.Key)
+
+	_, err = conf.GetBool("Key", false)
+	assert.EqualError(t, err, `config property Key must be a bool, but is "five"`)
+
+	_, err = conf.GetDuration("Key", 0)
+	assert.EqualError(t, err, `config property Key must be a duration, but is "five"`)
+
+	_, err = conf.GetMap("Key", nil)
+	assert.EqualError(t, err, `config property Key must be a map, but is "five"`)
+
+	_, err = ConfigMap{"Key": 1}.GetString("Key", "")
+	assert.EqualError(t, err, "config property Key must be a string, but is 1")
+
+	_, err = ConfigMap{"Key": 1}.GetStringSlice("Key", nil)
+	assert.EqualError(t, err, "config property Key must be a list of strings, but is 1")
+}
+
+func TestLoadConfigFromFS_GivenValidFilename_ReturnsConfig(t *testing.T) {
+	fsys := fstest.MapFS{
+		"migrations/migrations.yaml": {Data: []byte("provider: test\nmigrations:\n- name: Test\n  up: test.up.sql\n  down: test.down.sql")},
+	}
+
+	conf, err := LoadConfigFromFS(fsys, "migrations/migrations.yaml")
+	assert.NoError(t, err)
+	assert.Equal(t, "test", conf.Provider)
+	assert.Equal(t, 1, len(conf.Migrations))
+	assert.Equal(t, "test.up.sql", conf.Migrations[0].UpFile)
+}
+
+func TestLoadConfigFromFS_GivenMigrationWithoutTransaction_ReturnsConfig(t *testing.T) {
+	fsys := fstest.MapFS{
+		"migrations.yaml": {Data: []byte("provider: test\nmigrations:\n- name: One\n- name: Two\n  transaction: false")},
+	}
+
+	conf, err := LoadConfigFromFS(fsys, "migrations.yaml")
+	assert.NoError(t, err)
+	assert.True(t, conf.Migrations[0].InTransaction())
+	assert.False(t, conf.Migrations[1].InTransaction())
+}
+
+func TestLoadConfig_WithDiscovery_ReturnsFilesRelativeToFileContext(t *testing.T) {
+	dir := t.TempDir()
+	err := os.MkdirAll(path.Join(dir, "db", "sql"), 0755)
+	if err != nil {
+		panic(err)
+	}
+
+	files := map[string]string{
+		"db/migrations.yaml":     "provider: test\ndiscover: true\ndirectory: sql",
+		"db/sql/0001_a.up.sql":   "CREATE",
+		"db/sql/0001_a.down.sql": "DROP",
+	}
+	for name, content := range files {
+		err = ioutil.WriteFile(path.Join(dir, name), []byte(content), 0644)
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	conf, err := LoadConfig(dir, "db/migrations.yaml")
+	assert.NoError(t, err)
+	assert.Equal(t, []*Migration{
+		{Name: "0001_a", UpFile: "db/sql/0001_a.up.sql", DownFile: "db/sql/0001_a.down.sql"},
+	}, conf.Migrations)
+
+	content, err := NewFileReader(dir).Read(conf.Migrations[0].UpFile)
+	assert.NoError(t, err)
+	assert.Equal(t, "CREATE", content)
+}
+
+func TestLoadConfigFromFS_WithDiscovery_DiscoversMigrationsInFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"migrations/migrations.yaml":          {Data: []byte("provider: test\ndiscover: true\ndirectory: sql")},
+		"migrations/sql/0001_create.up.sql":   {Data: []byte("CREATE")},
+		"migrations/sql/0001_create.down.sql": {Data: []byte("DROP")},
+	}
+
+	conf, err := LoadConfigFromFS(fsys, "migrations/migrations.yaml")
+	assert.NoError(t, err)
+	assert.Equal(t, []*Migration{
+		{Name: "0001_create", UpFile: "migrations/sql/0001_create.up.sql", DownFile: "migrations/sql/0001_create.down.sql"},
+	}, conf.Migrations)
+
+	content, err := NewFSFileReader(fsys).Read(conf.Migrations[0].UpFile)
+	assert.NoError(t, err)
+	assert.Equal(t, "CREATE", content)
+}
+
+func TestLoadConfigFromFS_GivenNonExistantFilename_ReturnsError(t *testing.T) {
+	conf, err := LoadConfigFromFS(fstest.MapFS{}, "migrations.yaml")
+	assert.Nil(t, conf)
+	assert.True(t, os.IsNotExist(err))
+}
@@ -0,0 +1,92 @@
+package migrations
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"strings"
+)
+
+const (
+	// connectionStringKey is the key of the connection string in a ConfigMap.
+	connectionStringKey = "connectionString"
+
+	// connectionStringEnv is the environment variable the connection
+	// string is read from, if it isn't set in the ConfigMap.
+	connectionStringEnv = "CONNECTION_STRING"
+
+	// fileReferencePrefix prefixes a connection string
+	// which is a reference to a file, holding the value.
+	fileReferencePrefix = "file:"
+)
+
+// ErrNoConnectionString is returned by providers if neither connectionString,
+// nor the CONNECTION_STRING environment variable, are set.
+var ErrNoConnectionString = errors.New("no connection string was provided, either set connectionString in the config, or CONNECTION_STRING")
+
+// envReference matches ${VAR} and ${VAR:-default} references to environment variables.
+var envReference = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)(?::-([^}]*))?\}`)
+
+// RawConnectionString returns the value of connectionString, as given, and true,
+// or the CONNECTION_STRING environment variable and false, if it isn't set. Only
+// a value given by connectionString should be resolved by ResolveConnectionString,
+// as the environment variable is the connection string itself.
+func (m ConfigMap) RawConnectionString() (string, bool, error) {
+	v, err := m.GetString(connectionStringKey, "")
+	if err != nil {
+		return "", false, err
+	}
+
+	if v == "" {
+		return os.Getenv(connectionStringEnv), false, nil
+	}
+
+	return v, true, nil
+}
+
+// ResolveConnectionString returns the connection string, s, with any ${VAR} references
+// replaced by the value of the environment variable, VAR, which must be set, or
+// ${VAR:-default} by default, if VAR is unset or empty. If the value is a reference to
+// a file, such as "file:/run/secrets/db", the connection string is read from the file,
+// such as a mounted secret, ignoring trailing whitespace.
+func ResolveConnectionString(s string) (string, error) {
+	v, err := interpolate(s)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", connectionStringKey, err)
+	}
+
+	if !strings.HasPrefix(v, fileReferencePrefix) {
+		return v, nil
+	}
+
+	bytes, err := ioutil.ReadFile(strings.TrimPrefix(v, fileReferencePrefix))
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", connectionStringKey, err)
+	}
+
+	return strings.TrimRight(string(bytes), " \t\r\n"), nil
+}
+
+// interpolate replaces the references to environment variables in s with their values.
+func interpolate(s string) (string, error) {
+	var err error
+
+	result := envReference.ReplaceAllStringFunc(s, func(ref string) string {
+		match := envReference.FindStringSubmatch(ref)
+		name, hasDefault := match[1], strings.Contains(ref, ":-")
+
+		v, ok := os.LookupEnv(name)
+		switch {
+		case hasDefault && v == "":
+			return match[2]
+		case !ok && err == nil:
+			err = fmt.Errorf("environment variable %s is not set", name)
+		}
+
+		return v
+	})
+
+	return result, err
+}
@@ -0,0 +1,224 @@
+package migrations
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Templater is an optional interface a Provider can implement to
+// provide the initial content of migration files, created by CreateMigration.
+type Templater interface {
+	// Template returns the initial content of the file, used to run the
+	// migration with the given name in the direction, d.
+	Template(name string, d Direction) string
+}
+
+var (
+	// nonAlphanumeric matches characters which shouldn't be used in migration filenames.
+	nonAlphanumeric = regexp.MustCompile(`[^a-z0-9]+`)
+
+	// migrationsKey matches the top level "migrations" key of a config file.
+	migrationsKey = regexp.MustCompile(`^migrations:\s*(\[\s*\])?\s*(#.*)?$`)
+
+	// sequenceItem matches the first line of an item in a YAML sequence.
+	sequenceItem = regexp.MustCompile(`^(\s*)-(\s+)`)
+)
+
+// CreateMigration creates the up and down files of a new migration, with the given name,
+// in the directory, dir, then appends the migration to the config file, configFile. The
+// filenames are prefixed with the current UTC time, so they are ordered by creation.
+// If p implements Templater, it's used to provide the initial content of the files.
+//
+// The config file is updated in place, preserving its existing content and comments.
+// If the config discovers migrations, the files are instead created in its discovery
+// directory, and the migration is named after them, leaving the config file as is.
+func CreateMigration(dir, configFile, name string, p Provider) (*Migration, error) {
+	slug := strings.Trim(nonAlphanumeric.ReplaceAllString(strings.ToLower(name), "_"), "_")
+	if slug == "" {
+		return nil, fmt.Errorf("invalid migration name '%s'", name)
+	}
+
+	configContent, err := ioutil.ReadFile(configFile)
+	if err != nil {
+		return nil, err
+	}
+
+	var config Config
+	err = yaml.Unmarshal(configContent, &config)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, m := range config.Migrations {
+		if m.Name == name {
+			return nil, fmt.Errorf("a migration named '%s' already exists", name)
+		}
+	}
+
+	prefix := time.Now().UTC().Format("20060102150405") + "_" + slug
+	m := &Migration{
+		Name:     name,
+		UpFile:   prefix + ".up.sql",
+		DownFile: prefix + ".down.sql",
+	}
+
+	// When discovering migrations, the files are created in the discovery
+	// directory, named as they'd be discovered, and the config is left as is.
+	// As with LoadConfig, their paths are relative to dir, not the config file.
+	if config.Discover {
+		configDir, err := filepath.Rel(dir, path.Dir(configFile))
+		if err != nil {
+			return nil, err
+		}
+
+		m.Name = prefix
+		m.UpFile = path.Join(filepath.ToSlash(configDir), config.Directory, m.UpFile)
+		m.DownFile = path.Join(filepath.ToSlash(configDir), config.Directory, m.DownFile)
+	}
+
+	err = writeNewFile(path.Join(dir, m.UpFile), template(p, name, Up))
+	if err != nil {
+		return nil, err
+	}
+
+	err = writeNewFile(path.Join(dir, m.DownFile), template(p, name, Down))
+	if err != nil {
+		os.Remove(path.Join(dir, m.UpFile))
+		return nil, err
+	}
+
+	if config.Discover {
+		return m, nil
+	}
+
+	updatedConfig, err := appendMigration(string(configContent), m)
+	if err == nil {
+		err = ioutil.WriteFile(configFile, []byte(updatedConfig), 0644)
+	}
+
+	if err != nil {
+		os.Remove(path.Join(dir, m.UpFile))
+		os.Remove(path.Join(dir, m.DownFile))
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// template returns the initial content for a migration file, using p if it implements Templater.
+func template(p Provider, name string, d Direction) string {
+	if t, ok := p.(Templater); ok {
+		return t.Template(name, d)
+	}
+
+	return fmt.Sprintf("-- %s (%s)\n", name, d)
+}
+
+// writeNewFile writes content to a file, failing if it already exists.
+func writeNewFile(filename, content string) error {
+	file, err := os.OpenFile(filename, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return err
+	}
+
+	_, err = file.WriteString(content)
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	return file.Close()
+}
+
+// appendMigration appends m to the "migrations" sequence of the YAML config,
+// returning the updated config. Rather than re-encoding the config, the entry
+// is inserted as text, after the last item, using the same indentation, in
+// order to preserve the rest of the file.
+func appendMigration(config string, m *Migration) (string, error) {
+	lines := strings.Split(config, "\n")
+
+	keyLine := -1
+	for i, line := range lines {
+		if migrationsKey.MatchString(line) {
+			keyLine = i
+			break
+		}
+	}
+
+	// The item's indentation, and the indentation of the item's other keys.
+	itemIndent, keyIndent := "  - ", "    "
+	insertAt := len(lines)
+
+	if keyLine == -1 {
+		if strings.TrimSpace(lines[len(lines)-1]) == "" {
+			lines = lines[:len(lines)-1]
+		}
+
+		lines = append(lines, "migrations:")
+		insertAt = len(lines)
+	} else {
+		// An empty flow sequence, i.e. "migrations: []", is replaced with a block sequence.
+		lines[keyLine] = "migrations:"
+		insertAt = keyLine + 1
+		foundItem := false
+
+		for i := keyLine + 1; i < len(lines); i++ {
+			line := lines[i]
+			trimmed := strings.TrimSpace(line)
+			if trimmed == "" || (strings.HasPrefix(trimmed, "#") && line[0] != ' ') {
+				continue
+			}
+
+			// The block ends at the next top level key.
+			if line[0] != ' ' && line[0] != '\t' && line[0] != '-' {
+				break
+			}
+
+			if match := sequenceItem.FindStringSubmatch(line); match != nil && !foundItem {
+				itemIndent = match[0]
+				keyIndent = strings.Repeat(" ", len(match[0]))
+				foundItem = true
+			}
+
+			insertAt = i + 1
+		}
+	}
+
+	entry := []string{
+		itemIndent + "name: " + yamlScalar(m.Name),
+		keyIndent + "up: " + yamlScalar(m.UpFile),
+		keyIndent + "down: " + yamlScalar(m.DownFile),
+	}
+
+	updated := append(append(append([]string{}, lines[:insertAt]...), entry...), lines[insertAt:]...)
+	result := strings.Join(updated, "\n")
+	if !strings.HasSuffix(result, "\n") {
+		result += "\n"
+	}
+
+	// Ensure the entry was inserted in the correct place, as the
+	// config file may be formatted in a way which isn't supported.
+	var c Config
+	err := yaml.Unmarshal([]byte(result), &c)
+	if err != nil || len(c.Migrations) == 0 || *c.Migrations[len(c.Migrations)-1] != *m {
+		return "", errors.New("unable to append the migration to the config file, it must be added manually")
+	}
+
+	return result, nil
+}
+
+// yamlScalar returns s encoded as a YAML scalar, quoted if necessary.
+func yamlScalar(s string) string {
+	b, _ := yaml.Marshal(s)
+
+	return strings.TrimSuffix(string(b), "\n")
+}
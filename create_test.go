@@ -0,0 +1,205 @@
+package migrations
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAppendMigration(t *testing.T) {
+	testMigration := &Migration{
+		Name:     "Add Column",
+		UpFile:   "20210102030405_add_column.up.sql",
+		DownFile: "20210102030405_add_column.down.sql",
+	}
+
+	tests := []struct {
+		name     string
+		config   string
+		expected string
+	}{
+		{
+			name: "Indented Sequence",
+			config: `provider: mssql
+# The migrations, in order.
+migrations:
+  - name: Initial Creation
+    up: create_table.up.sql
+    down: create_table.down.sql
+`,
+			expected: `provider: mssql
+# The migrations, in order.
+migrations:
+  - name: Initial Creation
+    up: create_table.up.sql
+    down: create_table.down.sql
+  - name: Add Column
+    up: 20210102030405_add_column.up.sql
+    down: 20210102030405_add_column.down.sql
+`,
+		},
+		{
+			name: "Unindented Sequence Followed By Key",
+			config: `migrations:
+- name: Initial Creation
+  up: create_table.up.sql
+  down: create_table.down.sql
+
+# Provider config
+provider: mssql`,
+			expected: `migrations:
+- name: Initial Creation
+  up: create_table.up.sql
+  down: create_table.down.sql
+- name: Add Column
+  up: 20210102030405_add_column.up.sql
+  down: 20210102030405_add_column.down.sql
+
+# Provider config
+provider: mssql
+`,
+		},
+		{
+			name: "Empty Sequence",
+			config: `provider: mssql
+migrations: []
+`,
+			expected: `provider: mssql
+migrations:
+  - name: Add Column
+    up: 20210102030405_add_column.up.sql
+    down: 20210102030405_add_column.down.sql
+`,
+		},
+		{
+			name: "Missing Sequence",
+			config: `provider: mssql
+`,
+			expected: `provider: mssql
+migrations:
+  - name: Add Column
+    up: 20210102030405_add_column.up.sql
+    down: 20210102030405_add_column.down.sql
+`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := appendMigration(test.config, testMigration)
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, result)
+		})
+	}
+}
+
+func TestAppendMigration_GivenNameRequiringQuotes_QuotesName(t *testing.T) {
+	testMigration := &Migration{
+		Name:     "Fix: Add Column",
+		UpFile:   "up.sql",
+		DownFile: "down.sql",
+	}
+
+	result, err := appendMigration("migrations:\n", testMigration)
+	assert.NoError(t, err)
+	assert.Equal(t, "migrations:\n  - name: 'Fix: Add Column'\n    up: up.sql\n    down: down.sql\n", result)
+}
+
+func TestAppendMigration_GivenUnsupportedFormat_ReturnsError(t *testing.T) {
+	result, err := appendMigration("{provider: mssql, migrations: [{name: One}]}", &Migration{Name: "Two"})
+	assert.Equal(t, "", result)
+	assert.Error(t, err)
+}
+
+func TestCreateMigration_GivenName_CreatesFilesAndUpdatesConfig(t *testing.T) {
+	dir := t.TempDir()
+	configFile := path.Join(dir, "migrations.yaml")
+	err := ioutil.WriteFile(configFile, []byte("provider: test\nmigrations:\n"), 0644)
+	if err != nil {
+		panic(err)
+	}
+
+	m, err := CreateMigration(dir, configFile, "Add Column", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "Add Column", m.Name)
+	assert.True(t, strings.HasSuffix(m.UpFile, "_add_column.up.sql"))
+	assert.True(t, strings.HasSuffix(m.DownFile, "_add_column.down.sql"))
+
+	up, err := ioutil.ReadFile(path.Join(dir, m.UpFile))
+	assert.NoError(t, err)
+	assert.Equal(t, "-- Add Column (up)\n", string(up))
+
+	down, err := ioutil.ReadFile(path.Join(dir, m.DownFile))
+	assert.NoError(t, err)
+	assert.Equal(t, "-- Add Column (down)\n", string(down))
+
+	conf, err := LoadConfigFromFile(configFile)
+	assert.NoError(t, err)
+	assert.Equal(t, []*Migration{m}, conf.Migrations)
+}
+
+func TestCreateMigration_GivenExistingName_ReturnsError(t *testing.T) {
+	dir := t.TempDir()
+	configFile := path.Join(dir, "migrations.yaml")
+	err := ioutil.WriteFile(configFile, []byte("migrations:\n  - name: One\n"), 0644)
+	if err != nil {
+		panic(err)
+	}
+
+	m, err := CreateMigration(dir, configFile, "One", nil)
+	assert.Nil(t, m)
+	assert.EqualError(t, err, "a migration named 'One' already exists")
+}
+
+// testTemplater is a Provider which implements Templater.
+type testTemplater struct {
+	Provider
+}
+
+func (testTemplater) Template(name string, d Direction) string {
+	return "-- template: " + name + " " + string(d)
+}
+
+func TestCreateMigration_GivenTemplater_UsesTemplate(t *testing.T) {
+	dir := t.TempDir()
+	configFile := path.Join(dir, "migrations.yaml")
+	err := ioutil.WriteFile(configFile, []byte("migrations:\n"), 0644)
+	if err != nil {
+		panic(err)
+	}
+
+	m, err := CreateMigration(dir, configFile, "One", testTemplater{})
+	assert.NoError(t, err)
+
+	up, _ := ioutil.ReadFile(path.Join(dir, m.UpFile))
+	assert.Equal(t, "-- template: One up", string(up))
+}
+
+func TestCreateMigration_WithDiscoveryInSubdirectory_ReturnsPathsRelativeToDir(t *testing.T) {
+	dir := t.TempDir()
+	err := os.MkdirAll(path.Join(dir, "db", "sql"), 0755)
+	if err != nil {
+		panic(err)
+	}
+
+	configFile := path.Join(dir, "db", "migrations.yaml")
+	err = ioutil.WriteFile(configFile, []byte("provider: test\ndiscover: true\ndirectory: sql\n"), 0644)
+	if err != nil {
+		panic(err)
+	}
+
+	m, err := CreateMigration(dir, configFile, "One", nil)
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(m.UpFile, "db/sql/"))
+
+	conf, err := LoadConfig(dir, "db/migrations.yaml")
+	assert.NoError(t, err)
+	assert.Equal(t, []*Migration{m}, conf.Migrations)
+
+	_, err = NewFileReader(dir).Read(m.DownFile)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,117 @@
+package migrations
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// migrationFile matches the filenames of migrations which can be discovered,
+// in the format NNNN_name.up.sql or NNNN_name.down.sql.
+var migrationFile = regexp.MustCompile(`^((\d+)_.+)\.(up|down)\.sql$`)
+
+// DiscoveryError is returned by DiscoverMigrations if any migrations are missing
+// either their up or down file, or multiple migrations have the same version.
+type DiscoveryError struct {
+	Problems []string
+}
+
+func (e *DiscoveryError) Error() string {
+	return "invalid migration files: " + strings.Join(e.Problems, "; ")
+}
+
+// DiscoverMigrations scans the directory, dir, within the file context, fileContext, for
+// migration files named NNNN_name.up.sql and NNNN_name.down.sql, where NNNN is the version.
+// The migrations are returned ordered by version, named NNNN_name, with their files relative
+// to fileContext. Any orphaned up or down files are reported in a *DiscoveryError.
+func DiscoverMigrations(fileContext, dir string) ([]*Migration, error) {
+	files, err := os.ReadDir(path.Join(fileContext, dir))
+	if err != nil {
+		return nil, err
+	}
+
+	return discoverMigrations(files, dir)
+}
+
+// DiscoverMigrationsFS is the same as DiscoverMigrations, but scans the directory,
+// dir, within fsys, such as an embed.FS. The migrations' files are relative to fsys.
+func DiscoverMigrationsFS(fsys fs.FS, dir string) ([]*Migration, error) {
+	files, err := fs.ReadDir(fsys, path.Join(".", dir))
+	if err != nil {
+		return nil, err
+	}
+
+	return discoverMigrations(files, dir)
+}
+
+// discoverMigrations builds the migrations from files, the contents of the directory, dir.
+func discoverMigrations(files []fs.DirEntry, dir string) ([]*Migration, error) {
+	type discovered struct {
+		*Migration
+		version uint64
+	}
+
+	byName := make(map[string]*discovered)
+	var found []*discovered
+
+	for _, file := range files {
+		match := migrationFile.FindStringSubmatch(file.Name())
+		if file.IsDir() || match == nil {
+			continue
+		}
+
+		name, direction := match[1], match[3]
+		version, err := strconv.ParseUint(match[2], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration version in %s: %v", file.Name(), err)
+		}
+
+		d, ok := byName[name]
+		if !ok {
+			d = &discovered{Migration: &Migration{Name: name}, version: version}
+			byName[name] = d
+			found = append(found, d)
+		}
+
+		filename := path.Join(dir, file.Name())
+		if direction == string(Up) {
+			d.UpFile = filename
+		} else {
+			d.DownFile = filename
+		}
+	}
+
+	sort.SliceStable(found, func(i, j int) bool {
+		return found[i].version < found[j].version
+	})
+
+	var problems []string
+	ms := make([]*Migration, len(found))
+
+	for i, d := range found {
+		if d.UpFile == "" {
+			problems = append(problems, fmt.Sprintf("%s has no up file", d.DownFile))
+		}
+
+		if d.DownFile == "" {
+			problems = append(problems, fmt.Sprintf("%s has no down file", d.UpFile))
+		}
+
+		if i > 0 && found[i-1].version == d.version {
+			problems = append(problems, fmt.Sprintf("%s and %s have the same version", found[i-1].Name, d.Name))
+		}
+
+		ms[i] = d.Migration
+	}
+
+	if len(problems) > 0 {
+		return nil, &DiscoveryError{Problems: problems}
+	}
+
+	return ms, nil
+}
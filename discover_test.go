@@ -0,0 +1,108 @@
+package migrations
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// createFiles creates empty files, with the given names, in dir.
+func createFiles(dir string, names ...string) {
+	for _, name := range names {
+		err := ioutil.WriteFile(path.Join(dir, name), nil, 0644)
+		if err != nil {
+			panic(err)
+		}
+	}
+}
+
+func TestDiscoverMigrations_GivenMigrationFiles_ReturnsMigrationsOrderedByVersion(t *testing.T) {
+	dir := t.TempDir()
+	os.Mkdir(path.Join(dir, "sql"), 0755)
+	createFiles(path.Join(dir, "sql"),
+		"10_add_column.up.sql",
+		"10_add_column.down.sql",
+		"2_create_table.up.sql",
+		"2_create_table.down.sql",
+		"README.md",
+	)
+
+	ms, err := DiscoverMigrations(dir, "sql")
+	assert.NoError(t, err)
+	assert.Equal(t, []*Migration{
+		{Name: "2_create_table", UpFile: "sql/2_create_table.up.sql", DownFile: "sql/2_create_table.down.sql"},
+		{Name: "10_add_column", UpFile: "sql/10_add_column.up.sql", DownFile: "sql/10_add_column.down.sql"},
+	}, ms)
+}
+
+func TestDiscoverMigrations_GivenOrphanedFiles_ReturnsDiscoveryError(t *testing.T) {
+	dir := t.TempDir()
+	createFiles(dir,
+		"0001_create_table.up.sql",
+		"0002_add_column.down.sql",
+		"0003_one.up.sql",
+		"0003_one.down.sql",
+		"0003_two.up.sql",
+		"0003_two.down.sql",
+	)
+
+	ms, err := DiscoverMigrations(dir, "")
+	assert.Nil(t, ms)
+	assert.Equal(t, &DiscoveryError{
+		Problems: []string{
+			"0001_create_table.up.sql has no down file",
+			"0002_add_column.down.sql has no up file",
+			"0003_one and 0003_two have the same version",
+		},
+	}, err)
+}
+
+func TestDiscoverMigrations_GivenNonExistantDirectory_ReturnsIsNotExist(t *testing.T) {
+	ms, err := DiscoverMigrations(t.TempDir(), "missing")
+	assert.Nil(t, ms)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestLoadConfigFromFile_WithDiscovery_DiscoversMigrations(t *testing.T) {
+	dir := t.TempDir()
+	createFiles(dir, "0001_create_table.up.sql", "0001_create_table.down.sql")
+	configFile := path.Join(dir, "migrations.yaml")
+	ioutil.WriteFile(configFile, []byte("provider: test\ndiscover: true\n"), 0644)
+
+	conf, err := LoadConfigFromFile(configFile)
+	assert.NoError(t, err)
+	assert.Equal(t, []*Migration{
+		{Name: "0001_create_table", UpFile: "0001_create_table.up.sql", DownFile: "0001_create_table.down.sql"},
+	}, conf.Migrations)
+}
+
+func TestLoadConfigFromFile_WithDiscoveryAndListedMigrations_ReturnsError(t *testing.T) {
+	dir := t.TempDir()
+	configFile := path.Join(dir, "migrations.yaml")
+	ioutil.WriteFile(configFile, []byte("discover: true\nmigrations:\n  - name: One\n"), 0644)
+
+	conf, err := LoadConfigFromFile(configFile)
+	assert.Nil(t, conf)
+	assert.Error(t, err)
+}
+
+func TestCreateMigration_WithDiscovery_CreatesFilesInDirectory(t *testing.T) {
+	dir := t.TempDir()
+	os.Mkdir(path.Join(dir, "sql"), 0755)
+	configFile := path.Join(dir, "migrations.yaml")
+	configContent := "provider: test\ndiscover: true\ndirectory: sql\n"
+	ioutil.WriteFile(configFile, []byte(configContent), 0644)
+
+	m, err := CreateMigration(dir, configFile, "Add Column", nil)
+	assert.NoError(t, err)
+
+	conf, err := LoadConfigFromFile(configFile)
+	assert.NoError(t, err)
+	assert.Equal(t, []*Migration{m}, conf.Migrations)
+
+	content, _ := ioutil.ReadFile(configFile)
+	assert.Equal(t, configContent, string(content))
+}
@@ -0,0 +1,44 @@
+package migrations_test
+
+import (
+	"context"
+	"embed"
+	"io/fs"
+	"log"
+
+	"github.com/reecerussell/migrations"
+	"github.com/reecerussell/migrations/providers"
+
+	// providers
+	_ "github.com/reecerussell/migrations/providers/mssql"
+)
+
+//go:embed example
+var exampleFS embed.FS
+
+// This example applies the migrations in the example directory,
+// embedded into the binary, such as when a service starts up.
+func ExampleNewFSFileReader() {
+	// The config and migration files are relative to the example directory.
+	fsys, err := fs.Sub(exampleFS, "example")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	config, err := migrations.LoadConfigFromFS(fsys, "migrations.yaml")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	p, err := providers.Get(config.Provider, config.Config)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fr := migrations.NewFSFileReader(fsys)
+
+	err = migrations.Apply(context.Background(), config.Migrations, p, fr, "")
+	if err != nil {
+		log.Fatal(err)
+	}
+}
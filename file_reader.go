@@ -1,6 +1,7 @@
 package migrations
 
 import (
+	"io/fs"
 	"io/ioutil"
 	"path"
 )
@@ -30,3 +31,23 @@ func (fr *fileReader) Read(filename string) (string, error) {
 
 	return string(bytes), nil
 }
+
+// fsFileReader is an implementation of FileReader, which reads from an fs.FS.
+type fsFileReader struct {
+	fsys fs.FS
+}
+
+// NewFSFileReader returns a new instance of FileReader, which reads files from
+// fsys, such as an embed.FS. Filenames are relative to the root of fsys.
+func NewFSFileReader(fsys fs.FS) FileReader {
+	return &fsFileReader{fsys: fsys}
+}
+
+func (fr *fsFileReader) Read(filename string) (string, error) {
+	bytes, err := fs.ReadFile(fr.fsys, path.Clean(filename))
+	if err != nil {
+		return "", err
+	}
+
+	return string(bytes), nil
+}
@@ -3,6 +3,7 @@ package migrations
 import (
 	"os"
 	"testing"
+	"testing/fstest"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -32,3 +33,21 @@ func TestFileReaderRead_GivenInvalidFilePath_ReturnsIsNotExist(t *testing.T) {
 	assert.Equal(t, "", content)
 	assert.True(t, os.IsNotExist(err))
 }
+
+func TestFSFileReaderRead(t *testing.T) {
+	fsys := fstest.MapFS{
+		"sql/FileReader.sql": {Data: []byte("Hello World")},
+	}
+
+	fr := NewFSFileReader(fsys)
+	content, err := fr.Read("./sql/FileReader.sql")
+	assert.Equal(t, "Hello World", content)
+	assert.NoError(t, err)
+}
+
+func TestFSFileReaderRead_GivenInvalidFilePath_ReturnsIsNotExist(t *testing.T) {
+	fr := NewFSFileReader(fstest.MapFS{})
+	content, err := fr.Read("MissingFileName")
+	assert.Equal(t, "", content)
+	assert.True(t, os.IsNotExist(err))
+}
@@ -1,11 +1,14 @@
 module github.com/reecerussell/migrations
 
-go 1.15
+go 1.16
 
 require (
+	github.com/DATA-DOG/go-sqlmock v1.5.0
 	github.com/denisenkom/go-mssqldb v0.9.0
 	github.com/go-sql-driver/mysql v1.7.1
 	github.com/golang/mock v1.4.4
+	github.com/lib/pq v1.10.9
 	github.com/stretchr/testify v1.7.0
 	gopkg.in/yaml.v2 v2.4.0
+	modernc.org/sqlite v1.21.2
 )
@@ -0,0 +1,133 @@
+package migrations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrHistoryNotSupported is returned when a history operation, such as Baseline,
+// is used with a provider which doesn't implement the required interface.
+var ErrHistoryNotSupported = errors.New("the provider does not support modifying the migration history")
+
+// HistoryMarker is an optional interface a Provider can implement to record
+// a migration as applied, without running it, as used by Baseline and MarkApplied.
+type HistoryMarker interface {
+	// MarkApplied adds a record of the migration with the given name to the
+	// migration history, including the checksum of its up file's content, and
+	// the note, given by the Note option, recording why it was marked, which
+	// may be empty.
+	MarkApplied(ctx context.Context, name, content, note string) error
+}
+
+// HistoryUnmarker is an optional interface a Provider can implement to
+// remove the record of a migration from the migration history, without
+// rolling it back, as used by MarkUnapplied.
+type HistoryUnmarker interface {
+	// MarkUnapplied removes the record of the migration with
+	// the given name from the migration history.
+	MarkUnapplied(ctx context.Context, name string) error
+}
+
+// MarkApplied records the configured migration with the given name as applied,
+// without running it, using the given provider, p, which must implement
+// HistoryMarker. It's used to repair the migration history, i.e. once a
+// migration has been applied by hand. An error is returned if the
+// migration is already applied.
+//
+// If p implements Locker, the migration lock is held while the history is modified.
+func MarkApplied(ctx context.Context, cm []*Migration, p Provider, fr FileReader, name string, opts ...Option) error {
+	hm, ok := p.(HistoryMarker)
+	if !ok {
+		return ErrHistoryNotSupported
+	}
+
+	m, err := findConfigured(cm, name)
+	if err != nil {
+		return err
+	}
+
+	return withLock(ctx, p, opts, func(ctx context.Context, o *options, am []*Migration) error {
+		if isApplied(am, name) {
+			return fmt.Errorf("migration %s is already applied", name)
+		}
+
+		return markMigration(ctx, o, m, Up, func() error {
+			content, err := fr.Read(m.UpFile)
+			if err != nil {
+				return err
+			}
+
+			return hm.MarkApplied(ctx, m.Name, content, o.note)
+		})
+	})
+}
+
+// MarkUnapplied removes the record of the applied migration with the given name
+// from the migration history, without rolling it back, using the given provider, p,
+// which must implement HistoryUnmarker. It's used to repair the migration history,
+// i.e. once a migration has been rolled back by hand, or partially failed. The
+// migration needn't be configured, but an error is returned if it isn't applied.
+//
+// If p implements Locker, the migration lock is held while the history is modified.
+func MarkUnapplied(ctx context.Context, p Provider, name string, opts ...Option) error {
+	hu, ok := p.(HistoryUnmarker)
+	if !ok {
+		return ErrHistoryNotSupported
+	}
+
+	return withLock(ctx, p, opts, func(ctx context.Context, o *options, am []*Migration) error {
+		m := findApplied(am, name)
+		if m == nil {
+			return fmt.Errorf("migration %s is not applied", name)
+		}
+
+		return markMigration(ctx, o, m, Down, func() error {
+			return hu.MarkUnapplied(ctx, m.Name)
+		})
+	})
+}
+
+// withLock calls fn with the options and applied migrations, holding the migration
+// lock, if p implements Locker, for the duration of fn, and the context holding the
+// configured observer, if any.
+func withLock(ctx context.Context, p Provider, opts []Option, fn func(ctx context.Context, o *options, am []*Migration) error) error {
+	o, err := newOptions(opts)
+	if err != nil {
+		return err
+	}
+
+	ctx = o.context(ctx)
+
+	unlock, err := acquireLock(ctx, p, o.lockTimeout)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	am, err := p.GetAppliedMigrations(ctx)
+	if err != nil {
+		return err
+	}
+
+	return fn(ctx, o, am)
+}
+
+// markMigration calls fn to record the migration, m, as run in the direction, d,
+// without running it, notifying the Observer held by ctx of the outcome,
+// along with the note configured by o.
+func markMigration(ctx context.Context, o *options, m *Migration, d Direction, fn func() error) error {
+	err := fn()
+
+	ObserverFromContext(ctx).Observe(&Event{
+		Type:      EventMigrationMarked,
+		Time:      time.Now(),
+		Direction: d,
+		Migration: m.Name,
+		Err:       err,
+		Note:      o.note,
+	})
+
+	return err
+}
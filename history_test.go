@@ -0,0 +1,156 @@
+package migrations_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/reecerussell/migrations"
+	"github.com/reecerussell/migrations/mock"
+)
+
+// unmarkingProvider is a provider which implements migrations.HistoryUnmarker.
+type unmarkingProvider struct {
+	*mock.MockProvider
+	*mock.MockHistoryUnmarker
+}
+
+func TestMarkApplied_GivenUnappliedMigration_MarksMigrationAsApplied(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	testCtx := context.Background()
+	cm := []*migrations.Migration{{Name: "MyMigration", UpFile: "MyUp"}}
+
+	mockProvider := mock.NewMockProvider(ctrl)
+	mockProvider.EXPECT().GetAppliedMigrations(testCtx).Return([]*migrations.Migration{}, nil)
+
+	mockMarker := mock.NewMockHistoryMarker(ctrl)
+	mockMarker.EXPECT().MarkApplied(testCtx, "MyMigration", "MyContent", "").Return(nil)
+
+	mockFileReader := mock.NewMockFileReader(ctrl)
+	mockFileReader.EXPECT().Read("MyUp").Return("MyContent", nil)
+
+	p := &markingProvider{mockProvider, mockMarker}
+	err := migrations.MarkApplied(testCtx, cm, p, mockFileReader, "MyMigration")
+	assert.NoError(t, err)
+}
+
+func TestMarkApplied_GivenNote_AttachesNoteToMarkedEvent(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cm := []*migrations.Migration{{Name: "MyMigration", UpFile: "MyUp"}}
+
+	mockProvider := mock.NewMockProvider(ctrl)
+	mockProvider.EXPECT().GetAppliedMigrations(gomock.Any()).Return([]*migrations.Migration{}, nil)
+
+	mockMarker := mock.NewMockHistoryMarker(ctrl)
+	mockMarker.EXPECT().MarkApplied(gomock.Any(), "MyMigration", "MyContent", "applied by hand").Return(nil)
+
+	mockFileReader := mock.NewMockFileReader(ctrl)
+	mockFileReader.EXPECT().Read("MyUp").Return("MyContent", nil)
+
+	obs := &recordingObserver{}
+	p := &markingProvider{mockProvider, mockMarker}
+	err := migrations.MarkApplied(context.Background(), cm, p, mockFileReader, "MyMigration",
+		migrations.WithObserver(obs), migrations.Note("applied by hand"))
+	assert.NoError(t, err)
+
+	assert.Equal(t, []migrations.EventType{migrations.EventMigrationMarked}, obs.types())
+	assert.Equal(t, "applied by hand", obs.events[0].Note)
+}
+
+func TestMarkApplied_GivenAppliedMigration_ReturnsError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	testCtx := context.Background()
+	cm := []*migrations.Migration{{Name: "MyMigration", UpFile: "MyUp"}}
+
+	mockProvider := mock.NewMockProvider(ctrl)
+	mockProvider.EXPECT().GetAppliedMigrations(testCtx).Return(cm, nil)
+
+	p := &markingProvider{mockProvider, mock.NewMockHistoryMarker(ctrl)}
+	err := migrations.MarkApplied(testCtx, cm, p, nil, "MyMigration")
+	assert.EqualError(t, err, "migration MyMigration is already applied")
+}
+
+func TestMarkApplied_GivenUnknownMigration_ReturnsError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	p := &markingProvider{mock.NewMockProvider(ctrl), mock.NewMockHistoryMarker(ctrl)}
+	err := migrations.MarkApplied(context.Background(), nil, p, nil, "MyMigration")
+	assert.EqualError(t, err, "migration MyMigration does not exist")
+}
+
+func TestMarkApplied_GivenProviderWithoutHistoryMarker_ReturnsError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	err := migrations.MarkApplied(context.Background(), nil, mock.NewMockProvider(ctrl), nil, "MyMigration")
+	assert.Equal(t, migrations.ErrHistoryNotSupported, err)
+}
+
+func TestMarkUnapplied_GivenAppliedMigration_MarksMigrationAsUnapplied(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	testCtx := context.Background()
+
+	mockProvider := mock.NewMockProvider(ctrl)
+	mockProvider.EXPECT().GetAppliedMigrations(testCtx).Return([]*migrations.Migration{{Name: "MyMigration"}}, nil)
+
+	mockUnmarker := mock.NewMockHistoryUnmarker(ctrl)
+	mockUnmarker.EXPECT().MarkUnapplied(testCtx, "MyMigration").Return(nil)
+
+	p := &unmarkingProvider{mockProvider, mockUnmarker}
+	err := migrations.MarkUnapplied(testCtx, p, "MyMigration")
+	assert.NoError(t, err)
+}
+
+func TestMarkUnapplied_GivenNote_AttachesNoteToMarkedEvent(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockProvider := mock.NewMockProvider(ctrl)
+	mockProvider.EXPECT().GetAppliedMigrations(gomock.Any()).Return([]*migrations.Migration{{Name: "MyMigration"}}, nil)
+
+	mockUnmarker := mock.NewMockHistoryUnmarker(ctrl)
+	mockUnmarker.EXPECT().MarkUnapplied(gomock.Any(), "MyMigration").Return(nil)
+
+	obs := &recordingObserver{}
+	p := &unmarkingProvider{mockProvider, mockUnmarker}
+	err := migrations.MarkUnapplied(context.Background(), p, "MyMigration",
+		migrations.WithObserver(obs), migrations.Note("rolled back by hand"))
+	assert.NoError(t, err)
+
+	assert.Equal(t, []migrations.EventType{migrations.EventMigrationMarked}, obs.types())
+	assert.Equal(t, migrations.Down, obs.events[0].Direction)
+	assert.Equal(t, "rolled back by hand", obs.events[0].Note)
+}
+
+func TestMarkUnapplied_GivenUnappliedMigration_ReturnsError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	testCtx := context.Background()
+
+	mockProvider := mock.NewMockProvider(ctrl)
+	mockProvider.EXPECT().GetAppliedMigrations(testCtx).Return([]*migrations.Migration{}, nil)
+
+	p := &unmarkingProvider{mockProvider, mock.NewMockHistoryUnmarker(ctrl)}
+	err := migrations.MarkUnapplied(testCtx, p, "MyMigration")
+	assert.EqualError(t, err, "migration MyMigration is not applied")
+}
+
+func TestMarkUnapplied_GivenProviderWithoutHistoryUnmarker_ReturnsError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	err := migrations.MarkUnapplied(context.Background(), mock.NewMockProvider(ctrl), "MyMigration")
+	assert.Equal(t, migrations.ErrHistoryNotSupported, err)
+}
@@ -0,0 +1,62 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// DefaultLockTimeout is the time Apply and Rollback wait to acquire a
+// lock, from a Provider implementing Locker, unless configured otherwise.
+const DefaultLockTimeout = 30 * time.Second
+
+// Locker is an optional interface a Provider can implement to prevent
+// multiple processes from running migrations against the same database
+// concurrently. Apply and Rollback acquire the lock before reading the
+// applied migrations, and release it once they have finished.
+type Locker interface {
+	// Lock acquires an exclusive migration lock, waiting up to timeout
+	// for it to become available. If the lock is not acquired in time,
+	// a *LockError should be returned. If timeout is zero or less, Lock
+	// waits indefinitely, until the lock is acquired or ctx is done.
+	Lock(ctx context.Context, timeout time.Duration) error
+
+	// Unlock releases the lock acquired by Lock.
+	Unlock(ctx context.Context) error
+}
+
+// LockError is returned by a Locker, if the migration lock could
+// not be acquired within the timeout.
+type LockError struct {
+	Timeout time.Duration
+
+	// Holder describes who holds the lock, such as a session or connection
+	// id. It may be empty if the provider is unable to determine it.
+	Holder string
+}
+
+func (e *LockError) Error() string {
+	if e.Holder == "" {
+		return fmt.Sprintf("could not acquire migration lock within %s", e.Timeout)
+	}
+
+	return fmt.Sprintf("could not acquire migration lock within %s: locked by %s", e.Timeout, e.Holder)
+}
+
+// acquireLock acquires a lock from the provider, p, if it implements
+// Locker, returning a func used to release it.
+func acquireLock(ctx context.Context, p Provider, timeout time.Duration) (func(), error) {
+	l, ok := p.(Locker)
+	if !ok {
+		return func() {}, nil
+	}
+
+	err := l.Lock(ctx, timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	// Any error releasing the lock is ignored, as providers hold the
+	// lock on a connection, which releases the lock when closed.
+	return func() { l.Unlock(ctx) }, nil
+}
@@ -0,0 +1,93 @@
+package migrations_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/reecerussell/migrations"
+	"github.com/reecerussell/migrations/mock"
+)
+
+// lockingProvider is a provider which implements migrations.Locker.
+type lockingProvider struct {
+	*mock.MockProvider
+	*mock.MockLocker
+}
+
+func TestLockError_HavingHolder_ReturnsMessageWithHolder(t *testing.T) {
+	err := &migrations.LockError{Timeout: time.Second, Holder: "session 52"}
+	assert.Equal(t, "could not acquire migration lock within 1s: locked by session 52", err.Error())
+}
+
+func TestApply_GivenLocker_HoldsLockWhileApplying(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	testCtx := context.Background()
+	testMigration := &migrations.Migration{
+		Name:   "MyMigration",
+		UpFile: "MyFile",
+	}
+
+	mockProvider := mock.NewMockProvider(ctrl)
+	mockLocker := mock.NewMockLocker(ctrl)
+	mockFileReader := mock.NewMockFileReader(ctrl)
+
+	gomock.InOrder(
+		mockLocker.EXPECT().Lock(testCtx, time.Minute).Return(nil),
+		mockProvider.EXPECT().GetAppliedMigrations(testCtx).Return([]*migrations.Migration{}, nil),
+		mockFileReader.EXPECT().Read("MyFile").Return("My Migration Content", nil),
+		mockProvider.EXPECT().Apply(testCtx, "MyMigration", "My Migration Content").Return(nil),
+		mockLocker.EXPECT().Unlock(testCtx).Return(nil),
+	)
+
+	p := &lockingProvider{mockProvider, mockLocker}
+	err := migrations.Apply(testCtx, []*migrations.Migration{testMigration}, p, mockFileReader, "", migrations.LockTimeout(time.Minute))
+	assert.NoError(t, err)
+}
+
+func TestApply_FailsToAcquireLock_ReturnsLockError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	testCtx := context.Background()
+	testError := &migrations.LockError{Timeout: migrations.DefaultLockTimeout}
+
+	mockLocker := mock.NewMockLocker(ctrl)
+	mockLocker.EXPECT().Lock(testCtx, migrations.DefaultLockTimeout).Return(testError)
+
+	p := &lockingProvider{mock.NewMockProvider(ctrl), mockLocker}
+	err := migrations.Apply(testCtx, nil, p, nil, "")
+	assert.Equal(t, testError, err)
+}
+
+func TestRollback_GivenLocker_HoldsLockWhileRollingBack(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	testCtx := context.Background()
+	testMigration := &migrations.Migration{
+		Name:     "MyMigration",
+		DownFile: "MyFile",
+	}
+
+	mockProvider := mock.NewMockProvider(ctrl)
+	mockLocker := mock.NewMockLocker(ctrl)
+	mockFileReader := mock.NewMockFileReader(ctrl)
+
+	gomock.InOrder(
+		mockLocker.EXPECT().Lock(testCtx, migrations.DefaultLockTimeout).Return(nil),
+		mockProvider.EXPECT().GetAppliedMigrations(testCtx).Return([]*migrations.Migration{testMigration}, nil),
+		mockFileReader.EXPECT().Read("MyFile").Return("My Migration Content", nil),
+		mockProvider.EXPECT().Rollback(testCtx, "MyMigration", "My Migration Content").Return(nil),
+		mockLocker.EXPECT().Unlock(testCtx).Return(nil),
+	)
+
+	p := &lockingProvider{mockProvider, mockLocker}
+	err := migrations.Rollback(testCtx, []*migrations.Migration{testMigration}, p, mockFileReader, "")
+	assert.NoError(t, err)
+}
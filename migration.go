@@ -11,4 +11,19 @@ type Migration struct {
 	DateApplied time.Time
 	UpFile      string `yaml:"up"`
 	DownFile    string `yaml:"down"`
+
+	// Transaction determines whether the migration is run in a transaction.
+	// Unless set to false, for statements which can't be run in a transaction,
+	// such as CREATE INDEX ... WITH (ONLINE = ON), it is.
+	Transaction *bool `yaml:"transaction"`
+
+	// Checksum is the checksum of the content the migration was applied
+	// with, as recorded by the provider. Empty if none was recorded.
+	Checksum string
+}
+
+// InTransaction determines whether the migration is run in a
+// transaction, which it is unless Transaction is set to false.
+func (m *Migration) InTransaction() bool {
+	return m.Transaction == nil || *m.Transaction
 }
@@ -0,0 +1,85 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ../history.go
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	gomock "github.com/golang/mock/gomock"
+	reflect "reflect"
+)
+
+// MockHistoryMarker is a mock of HistoryMarker interface
+type MockHistoryMarker struct {
+	ctrl     *gomock.Controller
+	recorder *MockHistoryMarkerMockRecorder
+}
+
+// MockHistoryMarkerMockRecorder is the mock recorder for MockHistoryMarker
+type MockHistoryMarkerMockRecorder struct {
+	mock *MockHistoryMarker
+}
+
+// NewMockHistoryMarker creates a new mock instance
+func NewMockHistoryMarker(ctrl *gomock.Controller) *MockHistoryMarker {
+	mock := &MockHistoryMarker{ctrl: ctrl}
+	mock.recorder = &MockHistoryMarkerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockHistoryMarker) EXPECT() *MockHistoryMarkerMockRecorder {
+	return m.recorder
+}
+
+// MarkApplied mocks base method
+func (m *MockHistoryMarker) MarkApplied(ctx context.Context, name, content, note string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkApplied", ctx, name, content, note)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkApplied indicates an expected call of MarkApplied
+func (mr *MockHistoryMarkerMockRecorder) MarkApplied(ctx, name, content, note interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkApplied", reflect.TypeOf((*MockHistoryMarker)(nil).MarkApplied), ctx, name, content, note)
+}
+
+// MockHistoryUnmarker is a mock of HistoryUnmarker interface
+type MockHistoryUnmarker struct {
+	ctrl     *gomock.Controller
+	recorder *MockHistoryUnmarkerMockRecorder
+}
+
+// MockHistoryUnmarkerMockRecorder is the mock recorder for MockHistoryUnmarker
+type MockHistoryUnmarkerMockRecorder struct {
+	mock *MockHistoryUnmarker
+}
+
+// NewMockHistoryUnmarker creates a new mock instance
+func NewMockHistoryUnmarker(ctrl *gomock.Controller) *MockHistoryUnmarker {
+	mock := &MockHistoryUnmarker{ctrl: ctrl}
+	mock.recorder = &MockHistoryUnmarkerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockHistoryUnmarker) EXPECT() *MockHistoryUnmarkerMockRecorder {
+	return m.recorder
+}
+
+// MarkUnapplied mocks base method
+func (m *MockHistoryUnmarker) MarkUnapplied(ctx context.Context, name string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkUnapplied", ctx, name)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkUnapplied indicates an expected call of MarkUnapplied
+func (mr *MockHistoryUnmarkerMockRecorder) MarkUnapplied(ctx, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkUnapplied", reflect.TypeOf((*MockHistoryUnmarker)(nil).MarkUnapplied), ctx, name)
+}
@@ -1,4 +1,6 @@
 //go:generate mockgen -package=mock -source=../provider.go -destination=provider.go
 //go:generate mockgen -package=mock -source=../file_reader.go -destination=file_reader.go
+//go:generate mockgen -package=mock -source=../lock.go -destination=locker.go
+//go:generate mockgen -package=mock -source=../history.go -destination=history.go
 
 package mock
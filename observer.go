@@ -0,0 +1,109 @@
+package migrations
+
+import (
+	"context"
+	"time"
+)
+
+// EventType identifies the kind of an Event.
+type EventType string
+
+// Possible values of EventType.
+const (
+	// EventMigrationStarted is raised before a migration is run.
+	EventMigrationStarted EventType = "migration_started"
+
+	// EventMigrationSkipped is raised for a migration which doesn't need to
+	// be run, i.e. when applying a migration which has already been applied.
+	EventMigrationSkipped EventType = "migration_skipped"
+
+	// EventMigrationSucceeded is raised once a migration has been run.
+	EventMigrationSucceeded EventType = "migration_succeeded"
+
+	// EventMigrationFailed is raised if a migration fails to run.
+	EventMigrationFailed EventType = "migration_failed"
+
+	// EventMigrationMarked is raised once a migration has been recorded as
+	// run in the event's direction, without running it, i.e. by Baseline.
+	EventMigrationMarked EventType = "migration_marked"
+
+	// EventStatementExecuted is raised by providers once
+	// a statement of a migration has been executed.
+	EventStatementExecuted EventType = "statement_executed"
+)
+
+// Event describes the progress of migrations being run.
+type Event struct {
+	Type      EventType
+	Time      time.Time
+	Direction Direction
+	Migration string
+
+	// Statement is the statement executed, for EventStatementExecuted.
+	Statement string
+
+	// Duration is the time taken to run the migration, or execute
+	// the statement. It's not set for started or skipped events.
+	Duration time.Duration
+
+	// Err is the error which caused a migration, or statement, to fail.
+	Err error
+
+	// Compensating is true if the migration is being run to compensate
+	// for a failed migration, when running transactionally.
+	Compensating bool
+
+	// Note is the note given by the Note option, for EventMigrationMarked.
+	Note string
+
+	// Baseline is true if the migration is skipped by Baseline,
+	// as it's already recorded as applied.
+	Baseline bool
+}
+
+// Observer receives events describing the progress of migrations
+// being run by Apply and Rollback, as well as by providers.
+type Observer interface {
+	Observe(e *Event)
+}
+
+// observerKey is the context key of the Observer.
+type observerKey struct{}
+
+// ContextWithObserver returns a copy of ctx, holding the observer, o. This is
+// how Apply and Rollback pass an observer to providers, and can be used
+// to observe events without passing WithObserver to each call.
+func ContextWithObserver(ctx context.Context, o Observer) context.Context {
+	return context.WithValue(ctx, observerKey{}, o)
+}
+
+// ObserverFromContext returns the Observer held by ctx. If
+// there isn't one, an Observer which discards events is returned.
+func ObserverFromContext(ctx context.Context) Observer {
+	if o, ok := ctx.Value(observerKey{}).(Observer); ok {
+		return o
+	}
+
+	return nopObserver{}
+}
+
+// StatementExecuted notifies the Observer held by ctx that a statement,
+// of the migration with the given name, has been executed, having started
+// at the given time. Providers which execute a migration's content as a
+// whole, rather than statement by statement, report it as one statement.
+func StatementExecuted(ctx context.Context, d Direction, name, statement string, start time.Time, err error) {
+	ObserverFromContext(ctx).Observe(&Event{
+		Type:      EventStatementExecuted,
+		Time:      time.Now(),
+		Direction: d,
+		Migration: name,
+		Statement: statement,
+		Duration:  time.Since(start),
+		Err:       err,
+	})
+}
+
+// nopObserver is an Observer which discards all events.
+type nopObserver struct{}
+
+func (nopObserver) Observe(*Event) {}
@@ -0,0 +1,134 @@
+package migrations_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/reecerussell/migrations"
+	"github.com/reecerussell/migrations/mock"
+)
+
+// recordingObserver is an Observer which records the events it receives.
+type recordingObserver struct {
+	events []*migrations.Event
+}
+
+func (o *recordingObserver) Observe(e *migrations.Event) {
+	o.events = append(o.events, e)
+}
+
+func (o *recordingObserver) types() []migrations.EventType {
+	types := make([]migrations.EventType, len(o.events))
+	for i, e := range o.events {
+		types[i] = e.Type
+	}
+
+	return types
+}
+
+func TestApply_WithObserver_NotifiesObserverOfEachMigration(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	testCtx := context.Background()
+	appliedMigration := &migrations.Migration{Name: "Applied", UpFile: "AppliedUp"}
+	testMigration := &migrations.Migration{Name: "MyMigration", UpFile: "MyUp"}
+
+	mockProvider := mock.NewMockProvider(ctrl)
+	mockProvider.EXPECT().GetAppliedMigrations(gomock.Any()).Return([]*migrations.Migration{appliedMigration}, nil)
+	mockProvider.EXPECT().Apply(gomock.Any(), "MyMigration", "MyContent").Return(nil)
+
+	mockFileReader := mock.NewMockFileReader(ctrl)
+	mockFileReader.EXPECT().Read("MyUp").Return("MyContent", nil)
+
+	obs := &recordingObserver{}
+	cm := []*migrations.Migration{appliedMigration, testMigration}
+	err := migrations.Apply(testCtx, cm, mockProvider, mockFileReader, "", migrations.WithObserver(obs))
+	assert.NoError(t, err)
+
+	assert.Equal(t, []migrations.EventType{
+		migrations.EventMigrationSkipped,
+		migrations.EventMigrationStarted,
+		migrations.EventMigrationSucceeded,
+	}, obs.types())
+	assert.Equal(t, "Applied", obs.events[0].Migration)
+	assert.Equal(t, "MyMigration", obs.events[2].Migration)
+	assert.Equal(t, migrations.Up, obs.events[2].Direction)
+}
+
+func TestApply_WithObserverWhereMigrationFails_NotifiesObserverOfFailureAndCompensation(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	testCtx := context.Background()
+	testError := errors.New("an error occurred")
+	first := &migrations.Migration{Name: "First", UpFile: "FirstUp", DownFile: "FirstDown"}
+	second := &migrations.Migration{Name: "Second", UpFile: "SecondUp"}
+
+	mockProvider := mock.NewMockProvider(ctrl)
+	mockProvider.EXPECT().GetAppliedMigrations(gomock.Any()).Return([]*migrations.Migration{}, nil)
+	mockProvider.EXPECT().Apply(gomock.Any(), "First", "FirstContent").Return(nil)
+	mockProvider.EXPECT().Apply(gomock.Any(), "Second", "SecondContent").Return(testError)
+	mockProvider.EXPECT().Rollback(gomock.Any(), "First", "FirstDownContent").Return(nil)
+
+	mockFileReader := mock.NewMockFileReader(ctrl)
+	mockFileReader.EXPECT().Read("FirstUp").Return("FirstContent", nil)
+	mockFileReader.EXPECT().Read("SecondUp").Return("SecondContent", nil)
+	mockFileReader.EXPECT().Read("FirstDown").Return("FirstDownContent", nil)
+
+	obs := &recordingObserver{}
+	cm := []*migrations.Migration{first, second}
+	err := migrations.Apply(testCtx, cm, mockProvider, mockFileReader, "",
+		migrations.Transactional(true), migrations.WithObserver(obs))
+	assert.True(t, errors.Is(err, testError))
+
+	assert.Equal(t, []migrations.EventType{
+		migrations.EventMigrationStarted,
+		migrations.EventMigrationSucceeded,
+		migrations.EventMigrationStarted,
+		migrations.EventMigrationFailed,
+		migrations.EventMigrationStarted,
+		migrations.EventMigrationSucceeded,
+	}, obs.types())
+	assert.Equal(t, testError, obs.events[3].Err)
+	assert.True(t, obs.events[5].Compensating)
+	assert.Equal(t, migrations.Down, obs.events[5].Direction)
+}
+
+func TestApply_WithObserver_PassesObserverToProvider(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	testMigration := &migrations.Migration{Name: "MyMigration", UpFile: "MyUp"}
+
+	mockProvider := mock.NewMockProvider(ctrl)
+	mockProvider.EXPECT().GetAppliedMigrations(gomock.Any()).Return([]*migrations.Migration{}, nil)
+	mockProvider.EXPECT().Apply(gomock.Any(), "MyMigration", "MyContent").
+		DoAndReturn(func(ctx context.Context, name, content string) error {
+			migrations.StatementExecuted(ctx, migrations.Up, name, content, time.Now(), nil)
+			return nil
+		})
+
+	mockFileReader := mock.NewMockFileReader(ctrl)
+	mockFileReader.EXPECT().Read("MyUp").Return("MyContent", nil)
+
+	obs := &recordingObserver{}
+	err := migrations.Apply(context.Background(), []*migrations.Migration{testMigration}, mockProvider, mockFileReader, "", migrations.WithObserver(obs))
+	assert.NoError(t, err)
+
+	assert.Equal(t, migrations.EventStatementExecuted, obs.events[1].Type)
+	assert.Equal(t, "MyContent", obs.events[1].Statement)
+}
+
+func TestObserverFromContext_WithoutObserver_ReturnsObserver(t *testing.T) {
+	obs := migrations.ObserverFromContext(context.Background())
+	assert.NotNil(t, obs)
+
+	// shouldn't panic
+	obs.Observe(&migrations.Event{Type: migrations.EventMigrationStarted})
+}
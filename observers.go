@@ -0,0 +1,146 @@
+package migrations
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// textObserver is an Observer which writes human readable progress.
+type textObserver struct {
+	mu         sync.Mutex
+	w          io.Writer
+	statements bool
+}
+
+// NewTextObserver returns an Observer which writes the progress of
+// migrations to w, in a human readable format, as used by the CLI.
+func NewTextObserver(w io.Writer) Observer {
+	return &textObserver{w: w}
+}
+
+// NewVerboseTextObserver returns an Observer which writes the progress
+// of migrations to w, like NewTextObserver, as well as each statement
+// executed by the provider.
+func NewVerboseTextObserver(w io.Writer) Observer {
+	return &textObserver{w: w, statements: true}
+}
+
+func (o *textObserver) Observe(e *Event) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	switch e.Type {
+	case EventMigrationStarted:
+		fmt.Fprintf(o.w, "%s %s...\t", progressVerb(e), e.Migration)
+	case EventMigrationSkipped:
+		if e.Baseline {
+			fmt.Fprintf(o.w, "Baselining %s...\talready applied.\n", e.Migration)
+		} else {
+			fmt.Fprintf(o.w, "%s %s...\tskipping.\n", progressVerb(e), e.Migration)
+		}
+	case EventMigrationSucceeded:
+		fmt.Fprintf(o.w, "done.\n")
+	case EventMigrationFailed:
+		if e.Compensating {
+			fmt.Fprintf(o.w, "failed.\n")
+		} else if e.Direction == Down {
+			fmt.Fprintf(o.w, "\nFailed to rollback migration %s.\n", e.Migration)
+		} else {
+			fmt.Fprintf(o.w, "\nFailed to apply migration %s.\n", e.Migration)
+		}
+	case EventMigrationMarked:
+		state := "applied"
+		if e.Direction == Down {
+			state = "unapplied"
+		}
+
+		if e.Err != nil {
+			fmt.Fprintf(o.w, "Failed to mark %s as %s.\n", e.Migration, state)
+		} else {
+			fmt.Fprintf(o.w, "Marked %s as %s.\n", e.Migration, state)
+		}
+
+		if e.Note != "" {
+			fmt.Fprintf(o.w, "Note: %s\n", e.Note)
+		}
+	case EventStatementExecuted:
+		if !o.statements {
+			break
+		}
+
+		if e.Err != nil {
+			fmt.Fprintf(o.w, "Failed to execute the following statement:\n%s\n", e.Statement)
+		} else {
+			fmt.Fprintf(o.w, "Executed the following statement:\n%s\n", e.Statement)
+		}
+	}
+}
+
+// progressVerb returns the verb describing the migration of the event, e.
+func progressVerb(e *Event) string {
+	switch {
+	case e.Direction == Down:
+		return "Rolling back"
+	case e.Compensating:
+		return "Re-applying"
+	default:
+		return "Applying"
+	}
+}
+
+// jsonObserver is an Observer which writes events as JSON lines.
+type jsonObserver struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+// jsonEvent is the JSON representation of an Event.
+type jsonEvent struct {
+	Type         EventType `json:"type"`
+	Time         time.Time `json:"time"`
+	Direction    Direction `json:"direction,omitempty"`
+	Migration    string    `json:"migration,omitempty"`
+	Statement    string    `json:"statement,omitempty"`
+	DurationMS   *float64  `json:"durationMs,omitempty"`
+	Error        string    `json:"error,omitempty"`
+	Compensating bool      `json:"compensating,omitempty"`
+	Note         string    `json:"note,omitempty"`
+	Baseline     bool      `json:"baseline,omitempty"`
+}
+
+// NewJSONObserver returns an Observer which writes each event to w,
+// as a single line of JSON, for consumption by structured logging.
+func NewJSONObserver(w io.Writer) Observer {
+	return &jsonObserver{enc: json.NewEncoder(w)}
+}
+
+func (o *jsonObserver) Observe(e *Event) {
+	je := &jsonEvent{
+		Type:         e.Type,
+		Time:         e.Time.UTC(),
+		Direction:    e.Direction,
+		Migration:    e.Migration,
+		Statement:    e.Statement,
+		Compensating: e.Compensating,
+		Note:         e.Note,
+		Baseline:     e.Baseline,
+	}
+
+	if e.Type == EventMigrationSucceeded || e.Type == EventMigrationFailed || e.Type == EventStatementExecuted {
+		ms := float64(e.Duration) / float64(time.Millisecond)
+		je.DurationMS = &ms
+	}
+
+	if e.Err != nil {
+		je.Error = e.Err.Error()
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	// Errors are ignored, as an observer should never interrupt a migration.
+	o.enc.Encode(je)
+}
@@ -0,0 +1,108 @@
+package migrations
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTextObserver(t *testing.T) {
+	var buf bytes.Buffer
+	obs := NewTextObserver(&buf)
+
+	obs.Observe(&Event{Type: EventMigrationSkipped, Direction: Up, Migration: "First"})
+	obs.Observe(&Event{Type: EventMigrationStarted, Direction: Up, Migration: "Second"})
+	obs.Observe(&Event{Type: EventStatementExecuted, Direction: Up, Migration: "Second", Statement: "SELECT 1"})
+	obs.Observe(&Event{Type: EventMigrationSucceeded, Direction: Up, Migration: "Second"})
+	obs.Observe(&Event{Type: EventMigrationStarted, Direction: Up, Migration: "Third"})
+	obs.Observe(&Event{Type: EventMigrationFailed, Direction: Up, Migration: "Third"})
+	obs.Observe(&Event{Type: EventMigrationStarted, Direction: Down, Migration: "Second", Compensating: true})
+	obs.Observe(&Event{Type: EventMigrationFailed, Direction: Down, Migration: "Second", Compensating: true})
+	obs.Observe(&Event{Type: EventMigrationMarked, Direction: Up, Migration: "Fourth"})
+	obs.Observe(&Event{Type: EventMigrationMarked, Direction: Down, Migration: "Fourth", Err: errors.New("an error occurred")})
+	obs.Observe(&Event{Type: EventMigrationMarked, Direction: Up, Migration: "Fifth", Note: "applied by hand"})
+	obs.Observe(&Event{Type: EventMigrationSkipped, Direction: Up, Migration: "Sixth", Baseline: true})
+
+	expected := "Applying First...\tskipping.\n" +
+		"Applying Second...\tdone.\n" +
+		"Applying Third...\t\nFailed to apply migration Third.\n" +
+		"Rolling back Second...\tfailed.\n" +
+		"Marked Fourth as applied.\n" +
+		"Failed to mark Fourth as unapplied.\n" +
+		"Marked Fifth as applied.\n" +
+		"Note: applied by hand\n" +
+		"Baselining Sixth...\talready applied.\n"
+	assert.Equal(t, expected, buf.String())
+}
+
+func TestVerboseTextObserver_GivenStatementEvents_WritesStatements(t *testing.T) {
+	var buf bytes.Buffer
+	obs := NewVerboseTextObserver(&buf)
+
+	obs.Observe(&Event{Type: EventMigrationStarted, Direction: Up, Migration: "First"})
+	obs.Observe(&Event{Type: EventStatementExecuted, Direction: Up, Migration: "First", Statement: "SELECT 1"})
+	obs.Observe(&Event{Type: EventStatementExecuted, Direction: Up, Migration: "First", Statement: "SELECT x", Err: errors.New("an error occurred")})
+	obs.Observe(&Event{Type: EventMigrationFailed, Direction: Up, Migration: "First"})
+
+	expected := "Applying First...\tExecuted the following statement:\nSELECT 1\n" +
+		"Failed to execute the following statement:\nSELECT x\n" +
+		"\nFailed to apply migration First.\n"
+	assert.Equal(t, expected, buf.String())
+}
+
+func TestJSONObserver(t *testing.T) {
+	var buf bytes.Buffer
+	obs := NewJSONObserver(&buf)
+
+	now := time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)
+	obs.Observe(&Event{Type: EventMigrationStarted, Time: now, Direction: Up, Migration: "First"})
+	obs.Observe(&Event{
+		Type:      EventMigrationFailed,
+		Time:      now,
+		Direction: Up,
+		Migration: "First",
+		Duration:  1500 * time.Microsecond,
+		Err:       errors.New("an error occurred"),
+	})
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Equal(t, 2, len(lines))
+
+	var started map[string]interface{}
+	assert.NoError(t, json.Unmarshal([]byte(lines[0]), &started))
+	assert.Equal(t, map[string]interface{}{
+		"type":      "migration_started",
+		"time":      "2021-01-02T03:04:05Z",
+		"direction": "up",
+		"migration": "First",
+	}, started)
+
+	var failed map[string]interface{}
+	assert.NoError(t, json.Unmarshal([]byte(lines[1]), &failed))
+	assert.Equal(t, "migration_failed", failed["type"])
+	assert.Equal(t, 1.5, failed["durationMs"])
+	assert.Equal(t, "an error occurred", failed["error"])
+}
+
+func TestJSONObserver_GivenMarkedEventWithNote_WritesNote(t *testing.T) {
+	var buf bytes.Buffer
+	obs := NewJSONObserver(&buf)
+
+	now := time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)
+	obs.Observe(&Event{Type: EventMigrationMarked, Time: now, Direction: Up, Migration: "First", Note: "applied by hand"})
+
+	var marked map[string]interface{}
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &marked))
+	assert.Equal(t, map[string]interface{}{
+		"type":      "migration_marked",
+		"time":      "2021-01-02T03:04:05Z",
+		"direction": "up",
+		"migration": "First",
+		"note":      "applied by hand",
+	}, marked)
+}
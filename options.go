@@ -0,0 +1,110 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Option is used to configure the behaviour of Apply and Rollback.
+type Option func(*options)
+
+// options holds the configurable behaviour of Apply and Rollback.
+type options struct {
+	transactional   bool
+	verifyChecksums bool
+	lockTimeout     time.Duration
+	observer        Observer
+	note            string
+
+	// steps is the maximum number of migrations to run, if positive.
+	steps int
+}
+
+// newOptions returns an instance of options, with opts applied,
+// returning an error if any of the given options are invalid.
+func newOptions(opts []Option) (*options, error) {
+	o := &options{
+		lockTimeout: DefaultLockTimeout,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	if o.steps < 0 {
+		return nil, fmt.Errorf("the number of steps must be at least 1")
+	}
+
+	return o, nil
+}
+
+// Transactional determines whether a batch of migrations should be
+// compensated if one of them fails. When enabled, Apply rolls back
+// each migration it applied in the current run, and Rollback re-applies
+// each migration it rolled back, in reverse order.
+func Transactional(enabled bool) Option {
+	return func(o *options) {
+		o.transactional = enabled
+	}
+}
+
+// VerifyChecksums determines whether Apply should verify the checksums of
+// applied migrations before applying any, returning a *DriftError if any
+// applied migration's up file has changed since it was applied.
+func VerifyChecksums(enabled bool) Option {
+	return func(o *options) {
+		o.verifyChecksums = enabled
+	}
+}
+
+// LockTimeout sets the time Apply and Rollback wait to acquire the migration
+// lock, if the provider implements Locker. Defaults to DefaultLockTimeout.
+// A timeout of zero or less waits indefinitely, until the lock is acquired
+// or the context is done.
+func LockTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		o.lockTimeout = timeout
+	}
+}
+
+// Steps limits the number of migrations Apply and Rollback run to n, relative
+// to the current state of the database, i.e. Steps(1) applies the next pending
+// migration, or rolls back the last applied migration. As with the rest of a
+// rollback, the last applied migrations are those last in the configured order,
+// not the most recently applied according to the history. If a target is also
+// given, they stop at whichever is reached first. n must be at least 1.
+func Steps(n int) Option {
+	return func(o *options) {
+		if n < 1 {
+			n = -1
+		}
+
+		o.steps = n
+	}
+}
+
+// WithObserver sets the Observer notified of the progress of Apply and Rollback.
+// The observer is passed to the provider through the context, see ContextWithObserver.
+func WithObserver(obs Observer) Option {
+	return func(o *options) {
+		o.observer = obs
+	}
+}
+
+// Note sets a note, such as the reason for the change, which MarkApplied,
+// MarkUnapplied and Baseline attach to the events they raise. MarkApplied and
+// Baseline also store it with the history record, using HistoryMarker.
+func Note(note string) Option {
+	return func(o *options) {
+		o.note = note
+	}
+}
+
+// context returns ctx, holding the configured observer, if any.
+func (o *options) context(ctx context.Context) context.Context {
+	if o.observer == nil {
+		return ctx
+	}
+
+	return ContextWithObserver(ctx, o.observer)
+}
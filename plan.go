@@ -0,0 +1,123 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+)
+
+// Direction represents the direction migrations are run in.
+type Direction string
+
+// Possible values of Direction.
+const (
+	// Up is the direction of Apply.
+	Up Direction = "up"
+
+	// Down is the direction of Rollback.
+	Down Direction = "down"
+)
+
+// MigrationPlan describes the migrations which would be run, and in what order.
+type MigrationPlan struct {
+	Direction  Direction           `json:"direction"`
+	Migrations []*PlannedMigration `json:"migrations"`
+}
+
+// PlannedMigration is a migration in a MigrationPlan, containing the
+// file and content which would be executed.
+type PlannedMigration struct {
+	Name    string `json:"name"`
+	File    string `json:"file"`
+	Content string `json:"content"`
+}
+
+// Plan returns the migrations which Apply (given Up) or Rollback (given Down) would
+// run, up to the target (if any), using the given provider, p. No migrations are run.
+// Of the options, only Steps affects the plan.
+func Plan(ctx context.Context, cm []*Migration, p Provider, fr FileReader, d Direction, targetName string, opts ...Option) (*MigrationPlan, error) {
+	o, err := newOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	err = resolveTarget(cm, targetName)
+	if err != nil {
+		return nil, err
+	}
+
+	am, err := p.GetAppliedMigrations(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if d == Down && targetName != "" && !isApplied(am, targetName) {
+		return nil, fmt.Errorf("migration %s is not applied", targetName)
+	}
+
+	plan := &MigrationPlan{
+		Direction:  d,
+		Migrations: []*PlannedMigration{},
+	}
+
+	err = walk(cm, am, d, targetName, o.steps, func(m *Migration, pending bool) error {
+		if !pending {
+			return nil
+		}
+
+		filename := m.UpFile
+		if d == Down {
+			filename = m.DownFile
+		}
+
+		content, err := fr.Read(filename)
+		if err != nil {
+			return err
+		}
+
+		plan.Migrations = append(plan.Migrations, &PlannedMigration{
+			Name:    m.Name,
+			File:    filename,
+			Content: content,
+		})
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return plan, nil
+}
+
+// walk calls fn for each of the configured migrations, cm, in the order they'd
+// be run in the direction, d. A migration is pending if it has yet to be run
+// in that direction, according to the applied migrations, am. The walk stops
+// once the target (if any) has been reached, whether or not it was pending,
+// once the given number of steps (if positive) have been run, or fn returns an error.
+func walk(cm, am []*Migration, d Direction, targetName string, steps int, fn func(m *Migration, pending bool) error) error {
+	run := 0
+
+	for i := range cm {
+		m := cm[i]
+		if d == Down {
+			m = cm[len(cm)-1-i]
+		}
+
+		pending := isApplied(am, m.Name) == (d == Down)
+
+		err := fn(m, pending)
+		if err != nil {
+			return err
+		}
+
+		if pending {
+			run++
+		}
+
+		if (targetName != "" && targetName == m.Name) || (pending && run == steps) {
+			return nil
+		}
+	}
+
+	return nil
+}
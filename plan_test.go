@@ -0,0 +1,145 @@
+package migrations_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/reecerussell/migrations"
+	"github.com/reecerussell/migrations/mock"
+)
+
+func TestPlan_GivenUpDirection_ReturnsUnappliedMigrationsUpToTarget(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	testCtx := context.Background()
+	testMigrations := []*migrations.Migration{
+		{Name: "One", UpFile: "One.up"},
+		{Name: "Two", UpFile: "Two.up"},
+		{Name: "Three", UpFile: "Three.up"},
+	}
+
+	mockProvider := mock.NewMockProvider(ctrl)
+	mockProvider.EXPECT().GetAppliedMigrations(testCtx).Return([]*migrations.Migration{{Name: "One"}}, nil)
+
+	mockFileReader := mock.NewMockFileReader(ctrl)
+	mockFileReader.EXPECT().Read("Two.up").Return("Two Up", nil)
+
+	plan, err := migrations.Plan(testCtx, testMigrations, mockProvider, mockFileReader, migrations.Up, "Two")
+	assert.NoError(t, err)
+	assert.Equal(t, &migrations.MigrationPlan{
+		Direction: migrations.Up,
+		Migrations: []*migrations.PlannedMigration{
+			{Name: "Two", File: "Two.up", Content: "Two Up"},
+		},
+	}, plan)
+}
+
+func TestPlan_GivenDownDirection_ReturnsAppliedMigrationsInReverse(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	testCtx := context.Background()
+	testMigrations := []*migrations.Migration{
+		{Name: "One", DownFile: "One.down"},
+		{Name: "Two", DownFile: "Two.down"},
+		{Name: "Three", DownFile: "Three.down"},
+	}
+
+	mockProvider := mock.NewMockProvider(ctrl)
+	mockProvider.EXPECT().GetAppliedMigrations(testCtx).Return(testMigrations[:2], nil)
+
+	mockFileReader := mock.NewMockFileReader(ctrl)
+	mockFileReader.EXPECT().Read("Two.down").Return("Two Down", nil)
+	mockFileReader.EXPECT().Read("One.down").Return("One Down", nil)
+
+	plan, err := migrations.Plan(testCtx, testMigrations, mockProvider, mockFileReader, migrations.Down, "")
+	assert.NoError(t, err)
+	assert.Equal(t, &migrations.MigrationPlan{
+		Direction: migrations.Down,
+		Migrations: []*migrations.PlannedMigration{
+			{Name: "Two", File: "Two.down", Content: "Two Down"},
+			{Name: "One", File: "One.down", Content: "One Down"},
+		},
+	}, plan)
+}
+
+func TestPlan_GivenSteps_ReturnsNumberOfMigrations(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	testCtx := context.Background()
+	testMigrations := []*migrations.Migration{
+		{Name: "One", DownFile: "One.down"},
+		{Name: "Two", DownFile: "Two.down"},
+	}
+
+	mockProvider := mock.NewMockProvider(ctrl)
+	mockProvider.EXPECT().GetAppliedMigrations(testCtx).Return(testMigrations, nil)
+
+	mockFileReader := mock.NewMockFileReader(ctrl)
+	mockFileReader.EXPECT().Read("Two.down").Return("Two Down", nil)
+
+	plan, err := migrations.Plan(testCtx, testMigrations, mockProvider, mockFileReader, migrations.Down, "", migrations.Steps(1))
+	assert.NoError(t, err)
+	assert.Equal(t, &migrations.MigrationPlan{
+		Direction: migrations.Down,
+		Migrations: []*migrations.PlannedMigration{
+			{Name: "Two", File: "Two.down", Content: "Two Down"},
+		},
+	}, plan)
+}
+
+func TestPlan_GivenUnknownTarget_ReturnsNotFoundError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	testMigrations := []*migrations.Migration{{Name: "One"}}
+
+	mockProvider := mock.NewMockProvider(ctrl)
+
+	plan, err := migrations.Plan(context.Background(), testMigrations, mockProvider, nil, migrations.Up, "Onee")
+	assert.Nil(t, plan)
+	assert.EqualError(t, err, "migration Onee does not exist, did you mean One?")
+}
+
+func TestPlan_GivenMigrationWithMissingFile_ReturnsIsNotExists(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	testCtx := context.Background()
+	testMigration := &migrations.Migration{
+		Name:   "MyMigration",
+		UpFile: "MyFile",
+	}
+
+	mockProvider := mock.NewMockProvider(ctrl)
+	mockProvider.EXPECT().GetAppliedMigrations(testCtx).Return([]*migrations.Migration{}, nil)
+
+	mockFileReader := mock.NewMockFileReader(ctrl)
+	mockFileReader.EXPECT().Read("MyFile").Return("", os.ErrNotExist)
+
+	plan, err := migrations.Plan(testCtx, []*migrations.Migration{testMigration}, mockProvider, mockFileReader, migrations.Up, "")
+	assert.Nil(t, plan)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestPlan_FailsToGetAppliedMigrations_ReturnsError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	testCtx := context.Background()
+	testError := errors.New("an error occurred")
+
+	mockProvider := mock.NewMockProvider(ctrl)
+	mockProvider.EXPECT().GetAppliedMigrations(testCtx).Return(nil, testError)
+
+	plan, err := migrations.Plan(testCtx, nil, mockProvider, nil, migrations.Up, "")
+	assert.Nil(t, plan)
+	assert.Equal(t, testError, err)
+}
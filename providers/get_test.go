@@ -0,0 +1,50 @@
+package providers_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/reecerussell/migrations"
+	"github.com/reecerussell/migrations/providers"
+	_ "github.com/reecerussell/migrations/providers/mssql"
+	_ "github.com/reecerussell/migrations/providers/mysql"
+	_ "github.com/reecerussell/migrations/providers/postgres"
+)
+
+func TestGet_GivenInvalidConnectionString_ReturnsError(t *testing.T) {
+	if v, ok := os.LookupEnv("CONNECTION_STRING"); ok {
+		os.Unsetenv("CONNECTION_STRING")
+		t.Cleanup(func() {
+			os.Setenv("CONNECTION_STRING", v)
+		})
+	}
+
+	tests := []struct {
+		name     string
+		conf     migrations.ConfigMap
+		expected string
+	}{
+		{
+			name:     "Without Connection String",
+			conf:     nil,
+			expected: migrations.ErrNoConnectionString.Error(),
+		},
+		{
+			name:     "Given Unset Variable",
+			conf:     migrations.ConfigMap{"connectionString": "${TEST_DB_URL_UNSET}"},
+			expected: "connectionString: environment variable TEST_DB_URL_UNSET is not set",
+		},
+	}
+
+	for _, provider := range []string{"mssql", "mysql", "postgres"} {
+		for _, test := range tests {
+			t.Run(provider+"/"+test.name, func(t *testing.T) {
+				p, err := providers.Get(provider, test.conf)
+				assert.Nil(t, p)
+				assert.EqualError(t, err, test.expected)
+			})
+		}
+	}
+}
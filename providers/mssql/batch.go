@@ -0,0 +1,120 @@
+package mssql
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// batchSeparator matches a line which separates batches, i.e. "GO", or "GO n"
+// to execute the batch n times. As with sqlcmd, it's case insensitive and
+// may be surrounded by whitespace, or followed by a comment.
+var batchSeparator = regexp.MustCompile(`(?i)^\s*GO(?:\s+(\d+))?\s*(?:--.*)?$`)
+
+// splitBatches splits the content of a migration into batches, separated by
+// GO lines, as supported by sqlcmd and SSMS. GO is only recognised at the start
+// of a line, outside of string literals, quoted identifiers and block comments.
+// A batch followed by "GO n" is repeated n times. Empty batches are omitted.
+func splitBatches(content string) ([]string, error) {
+	var (
+		batches []string
+		current strings.Builder
+		lexer   batchLexer
+	)
+
+	add := func(count int) {
+		batch := strings.TrimSpace(current.String())
+		current.Reset()
+
+		if batch == "" {
+			return
+		}
+
+		for i := 0; i < count; i++ {
+			batches = append(batches, batch)
+		}
+	}
+
+	for _, line := range strings.SplitAfter(content, "\n") {
+		if lexer.inCode() {
+			match := batchSeparator.FindStringSubmatch(strings.TrimRight(line, "\r\n"))
+			if match != nil {
+				count := 1
+				if match[1] != "" {
+					n, err := strconv.Atoi(match[1])
+					if err != nil || n < 1 {
+						return nil, fmt.Errorf("invalid batch count: %s", strings.TrimSpace(line))
+					}
+
+					count = n
+				}
+
+				add(count)
+				continue
+			}
+		}
+
+		lexer.scan(line)
+		current.WriteString(line)
+	}
+
+	add(1)
+
+	return batches, nil
+}
+
+// batchLexer tracks whether the content scanned so far ends within a
+// string literal, quoted identifier or block comment, which may span lines.
+type batchLexer struct {
+	// quote is the character closing the current string
+	// literal, or quoted identifier, if any.
+	quote byte
+
+	// depth is the depth of the current block comment, as they can be nested.
+	depth int
+}
+
+// inCode determines whether the lexer is outside of any
+// string literal, quoted identifier or block comment.
+func (l *batchLexer) inCode() bool {
+	return l.quote == 0 && l.depth == 0
+}
+
+// scan updates the state of the lexer with the given line.
+func (l *batchLexer) scan(line string) {
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		next := byte(0)
+		if i+1 < len(line) {
+			next = line[i+1]
+		}
+
+		switch {
+		case l.quote != 0:
+			// Quotes are escaped by doubling them, i.e. 'it''s' or [a]]b].
+			if c == l.quote {
+				if next == l.quote {
+					i++
+				} else {
+					l.quote = 0
+				}
+			}
+		case c == '/' && next == '*':
+			l.depth++
+			i++
+		case l.depth > 0:
+			if c == '*' && next == '/' {
+				l.depth--
+				i++
+			}
+		case c == '-' && next == '-':
+			// The rest of the line is a comment.
+			return
+		case c == '\'' || c == '"':
+			l.quote = c
+		case c == '[':
+			l.quote = ']'
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package mssql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitBatches(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected []string
+	}{
+		{
+			name:     "Empty",
+			content:  "",
+			expected: nil,
+		},
+		{
+			name:     "Single batch without separator",
+			content:  "CREATE TABLE a (id INT);\nCREATE TABLE b (id INT);",
+			expected: []string{"CREATE TABLE a (id INT);\nCREATE TABLE b (id INT);"},
+		},
+		{
+			name:     "Multiple batches",
+			content:  "CREATE TABLE a (id INT);\nGO\nCREATE VIEW b AS SELECT id FROM a;\nGO\n",
+			expected: []string{"CREATE TABLE a (id INT);", "CREATE VIEW b AS SELECT id FROM a;"},
+		},
+		{
+			name:     "Separator with whitespace, comment and CRLF",
+			content:  "SELECT 1;\r\n  go  -- next batch\r\nSELECT 2;\r\n",
+			expected: []string{"SELECT 1;", "SELECT 2;"},
+		},
+		{
+			name:     "Separator with count",
+			content:  "INSERT INTO a VALUES (1);\nGO 3\nSELECT 1;",
+			expected: []string{"INSERT INTO a VALUES (1);", "INSERT INTO a VALUES (1);", "INSERT INTO a VALUES (1);", "SELECT 1;"},
+		},
+		{
+			name:     "Empty batches",
+			content:  "GO\n\nGO\nSELECT 1;\nGO\nGO",
+			expected: []string{"SELECT 1;"},
+		},
+		{
+			name:     "GO within a line",
+			content:  "SELECT 1 AS GO;\nGOTO label;\nGO",
+			expected: []string{"SELECT 1 AS GO;\nGOTO label;"},
+		},
+		{
+			name:     "GO in multi-line string",
+			content:  "INSERT INTO a VALUES ('line\nGO\nit''s');\nGO\nSELECT 1;",
+			expected: []string{"INSERT INTO a VALUES ('line\nGO\nit''s');", "SELECT 1;"},
+		},
+		{
+			name:     "GO in block comment",
+			content:  "/* a comment\nGO\n/* nested */\nGO\n*/\nSELECT 1;\nGO\nSELECT 2;",
+			expected: []string{"/* a comment\nGO\n/* nested */\nGO\n*/\nSELECT 1;", "SELECT 2;"},
+		},
+		{
+			name:     "Quotes in line comment are ignored",
+			content:  "SELECT 1; -- it's a comment\nGO\nSELECT 2;",
+			expected: []string{"SELECT 1; -- it's a comment", "SELECT 2;"},
+		},
+		{
+			name:     "GO in quoted identifier",
+			content:  "CREATE TABLE [a\nGO\n]]b] (id INT);\nGO",
+			expected: []string{"CREATE TABLE [a\nGO\n]]b] (id INT);"},
+		},
+		{
+			name: "Stored procedure",
+			content: "CREATE PROCEDURE CountPeople AS\nBEGIN\n\tSELECT COUNT(*) FROM People;\nEND\nGO\n" +
+				"GRANT EXECUTE ON CountPeople TO public;\n",
+			expected: []string{
+				"CREATE PROCEDURE CountPeople AS\nBEGIN\n\tSELECT COUNT(*) FROM People;\nEND",
+				"GRANT EXECUTE ON CountPeople TO public;",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			batches, err := splitBatches(test.content)
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, batches)
+		})
+	}
+}
+
+func TestSplitBatches_GivenZeroCount_ReturnsError(t *testing.T) {
+	batches, err := splitBatches("SELECT 1;\nGO 0\n")
+	assert.Nil(t, batches)
+	assert.EqualError(t, err, "invalid batch count: GO 0")
+}
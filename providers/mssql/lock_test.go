@@ -0,0 +1,36 @@
+package mssql
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLock_GivenTimeout_PassesMillisecondsToGetAppLock(t *testing.T) {
+	tests := []struct {
+		timeout      time.Duration
+		milliseconds int64
+	}{
+		{timeout: 1500 * time.Millisecond, milliseconds: 1500},
+		{timeout: 0, milliseconds: -1},
+		{timeout: -time.Second, milliseconds: -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.timeout.String(), func(t *testing.T) {
+			p, mock := newMockProvider(t)
+
+			mock.ExpectQuery("sp_getapplock").
+				WithArgs(sql.Named("resource", "migrations:"+defaultHistoryTableName), sql.Named("timeout", tt.milliseconds)).
+				WillReturnRows(sqlmock.NewRows([]string{"result"}).AddRow(0))
+
+			err := p.Lock(context.Background(), tt.timeout)
+			assert.NoError(t, err)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -3,8 +3,9 @@ package mssql
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
-	"os"
+	"time"
 
 	"github.com/reecerussell/migrations"
 	"github.com/reecerussell/migrations/providers"
@@ -15,6 +16,11 @@ import (
 
 const defaultHistoryTableName = "__MigrationHistory"
 
+// errMaxOpenConns is returned by New if maxOpenConns is 1, as the migration
+// lock holds a connection from the pool for the whole run, which would leave
+// none to run the migrations on.
+var errMaxOpenConns = errors.New("maxOpenConns must be at least 2, as the migration lock holds a connection")
+
 func init() {
 	providers.Add("mssql", New)
 }
@@ -23,20 +29,84 @@ func init() {
 type MSSQL struct {
 	ConnectionString string
 	HistoryTableName string
+
+	// IsolationLevel is the isolation level of the transactions migrations
+	// are run in. Defaults to sql.LevelReadUncommitted.
+	IsolationLevel sql.IsolationLevel
+
+	// MaxOpenConns and MaxIdleConns configure the connection pool, which is
+	// opened on first use and reused until Close is called. Zero values
+	// leave the database/sql defaults in place.
+	MaxOpenConns int
+	MaxIdleConns int
+
+	// db is the connection pool, and lockConn holds the
+	// connection, taken from db, which owns the migration lock.
+	db       *sql.DB
+	lockConn *sql.Conn
+
+	// historyTableReady is set once the history table has been
+	// ensured, so it's only checked once per connection pool.
+	historyTableReady bool
 }
 
 // New returns a new instabnce of MSSQL. Implementing providers.ConstructorFunc,
-// New takes a migrations.ConfigMap, which is used to populate HistoryTableName.
-func New(conf migrations.ConfigMap) migrations.Provider {
-	historyTableName := defaultHistoryTableName
-	if v, _ := conf.String("historyTableName"); v != "" {
-		historyTableName = v
+// New takes a migrations.ConfigMap, which is used to populate ConnectionString,
+// resolved using migrations.ResolveConnectionString, falling back to CONNECTION_STRING,
+// as given, HistoryTableName and IsolationLevel, as well as the connection pool's
+// size. An error is returned if the ConfigMap is invalid, or neither connection
+// string is set.
+func New(conf migrations.ConfigMap) (migrations.Provider, error) {
+	p := &MSSQL{}
+
+	connectionString, fromConfig, err := conf.RawConnectionString()
+	if err != nil {
+		return nil, err
+	}
+
+	if connectionString == "" {
+		return nil, migrations.ErrNoConnectionString
+	}
+
+	if fromConfig {
+		connectionString, err = migrations.ResolveConnectionString(connectionString)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	p.ConnectionString = connectionString
+
+	p.HistoryTableName, err = conf.GetString("historyTableName", defaultHistoryTableName)
+	if err != nil {
+		return nil, err
+	}
+
+	isolationLevel, err := conf.GetString("isolationLevel", sql.LevelReadUncommitted.String())
+	if err != nil {
+		return nil, err
 	}
 
-	return &MSSQL{
-		ConnectionString: os.Getenv("CONNECTION_STRING"),
-		HistoryTableName: historyTableName,
+	p.IsolationLevel, err = migrations.ParseIsolationLevel(isolationLevel)
+	if err != nil {
+		return nil, err
 	}
+
+	p.MaxOpenConns, err = conf.GetInt("maxOpenConns", 0)
+	if err != nil {
+		return nil, err
+	}
+
+	if p.MaxOpenConns == 1 {
+		return nil, errMaxOpenConns
+	}
+
+	p.MaxIdleConns, err = conf.GetInt("maxIdleConns", 0)
+	if err != nil {
+		return nil, err
+	}
+
+	return p, nil
 }
 
 // GetAppliedMigrations queries the migration history table for all applied migrations.
@@ -48,116 +118,355 @@ func (p *MSSQL) GetAppliedMigrations(ctx context.Context) ([]*migrations.Migrati
 
 	p.ensureHistoryTable(ctx, db)
 
-	query := fmt.Sprintf("SELECT [Id], [Name], [DateApplied] FROM [%s];", p.HistoryTableName)
+	query := fmt.Sprintf("SELECT [Id], [Name], [DateApplied], [Checksum] FROM [%s];", p.HistoryTableName)
 	rows, err := db.QueryContext(ctx, query)
 	if err != nil {
 		return nil, err
 	}
+	defer rows.Close()
 
 	var appliedMigrations []*migrations.Migration
 
 	for rows.Next() {
 		var m migrations.Migration
+		var checksum sql.NullString
 
 		err := rows.Scan(
 			&m.ID,
 			&m.Name,
 			&m.DateApplied,
+			&checksum,
 		)
 		if err != nil {
 			return nil, err
 		}
 
+		m.Checksum = checksum.String
 		appliedMigrations = append(appliedMigrations, &m)
 	}
 
 	return appliedMigrations, nil
 }
 
-// ensureHistoryTable ensures the table with the name historyTableName exists.
+// ensureHistoryTable ensures the table with the name historyTableName exists,
+// adding the Checksum and Note columns to tables created by previous versions.
 // Should be provided a valid instance of *sql.DB.
 func (p *MSSQL) ensureHistoryTable(ctx context.Context, db *sql.DB) {
+	if p.historyTableReady {
+		return
+	}
+
 	query := fmt.Sprintf(
-		`IF NOT EXISTS (SELECT [name] FROM sys.tables WHERE [name] = '%s')
+		`IF NOT EXISTS (SELECT [name] FROM sys.tables WHERE [name] = '%[1]s')
 		BEGIN
-			CREATE TABLE [%s] (
+			CREATE TABLE [%[1]s] (
 				[Id] INT NOT NULL IDENTITY(1,1) PRIMARY KEY,
 				[Name] VARCHAR(255) NOT NULL,
-				[DateApplied] DATETIME NOT NULL
+				[DateApplied] DATETIME NOT NULL,
+				[Checksum] VARCHAR(64) NULL,
+				[Note] NVARCHAR(MAX) NULL
 			);
+		END
+		IF COL_LENGTH('%[1]s', 'Checksum') IS NULL
+		BEGIN
+			ALTER TABLE [%[1]s] ADD [Checksum] VARCHAR(64) NULL;
+		END
+		IF COL_LENGTH('%[1]s', 'Note') IS NULL
+		BEGIN
+			ALTER TABLE [%[1]s] ADD [Note] NVARCHAR(MAX) NULL;
 		END`,
 		p.HistoryTableName,
-		p.HistoryTableName,
 	)
 
 	// This should never return an error, as it's given a valid
 	// *sql.DB instance, with an open connection. Plus the query
-	// is valid.
-	db.ExecContext(ctx, query)
+	// is valid. If it does, the table is ensured again next time.
+	if _, err := db.ExecContext(ctx, query); err == nil {
+		p.historyTableReady = true
+	}
 }
 
 // Apply applies the migration, m, to the database, as well as
-// adding a record to the migration history table.
+// adding a record to the migration history table, including
+// the checksum of content.
 func (p *MSSQL) Apply(ctx context.Context, name, content string) error {
 	db, err := p.openConn(ctx)
 	if err != nil {
 		return err
 	}
 
-	tx, _ := db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelReadUncommitted})
-	_, err = tx.ExecContext(ctx, content)
-	if err != nil {
-		tx.Rollback()
-		return err
-	}
+	p.ensureHistoryTable(ctx, db)
 
-	query := fmt.Sprintf("INSERT INTO [%s] ([Name],[DateApplied]) VALUES (@name, GETUTCDATE());", p.HistoryTableName)
-	_, err = tx.ExecContext(ctx, query, sql.Named("name", name))
+	batches, err := splitBatches(content)
 	if err != nil {
-		tx.Rollback()
 		return err
 	}
 
-	tx.Commit()
+	query := fmt.Sprintf("INSERT INTO [%s] ([Name],[DateApplied],[Checksum]) VALUES (@name, GETUTCDATE(), @checksum);", p.HistoryTableName)
 
-	return nil
+	return p.run(ctx, db, migrations.Up, name, batches, query, sql.Named("name", name), sql.Named("checksum", migrations.Checksum(content)))
 }
 
 // Rollback rolls back the migration, m, then removed the
 // record from the migration history table.
 func (p *MSSQL) Rollback(ctx context.Context, name, content string) error {
-	var err error
-
 	db, err := p.openConn(ctx)
 	if err != nil {
 		return err
 	}
 
-	tx, _ := db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelReadUncommitted})
-	_, err = tx.ExecContext(ctx, content)
+	batches, err := splitBatches(content)
 	if err != nil {
-		tx.Rollback()
 		return err
 	}
 
 	query := fmt.Sprintf("DELETE FROM [%s] WHERE [Name] = @name;", p.HistoryTableName)
-	_, err = tx.ExecContext(ctx, query, sql.Named("name", name))
+
+	return p.run(ctx, db, migrations.Down, name, batches, query, sql.Named("name", name))
+}
+
+// run executes the batches of the migration with the given name, then updates the
+// migration history, using the query and its args, in a transaction. If ctx says
+// the migration isn't run in a transaction, both are executed on a single connection,
+// and the history is only updated once all of the batches have succeeded.
+func (p *MSSQL) run(ctx context.Context, db *sql.DB, d migrations.Direction, name string, batches []string, query string, args ...interface{}) error {
+	if !migrations.TransactionFromContext(ctx) {
+		conn, err := db.Conn(ctx)
+		if err != nil {
+			return &migrations.TxError{Migration: name, Phase: migrations.TxBegin, Err: err}
+		}
+		defer conn.Close()
+
+		err = p.execBatches(ctx, conn, d, name, batches)
+		if err != nil {
+			return &migrations.TxError{Migration: name, Phase: migrations.TxExec, Err: err}
+		}
+
+		_, err = conn.ExecContext(ctx, query, args...)
+		if err != nil {
+			return &migrations.TxError{Migration: name, Phase: migrations.TxHistory, Err: err}
+		}
+
+		return nil
+	}
+
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{Isolation: p.IsolationLevel})
+	if err != nil {
+		return &migrations.TxError{Migration: name, Phase: migrations.TxBegin, Err: err}
+	}
+
+	err = p.execBatches(ctx, tx, d, name, batches)
 	if err != nil {
 		tx.Rollback()
+		return &migrations.TxError{Migration: name, Phase: migrations.TxExec, Err: err}
+	}
+
+	_, err = tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		tx.Rollback()
+		return &migrations.TxError{Migration: name, Phase: migrations.TxHistory, Err: err}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return &migrations.TxError{Migration: name, Phase: migrations.TxCommit, Err: err}
+	}
+
+	return nil
+}
+
+// MarkApplied adds a record of the migration to the migration
+// history table, including the checksum of content and the note, if any,
+// without applying it.
+func (p *MSSQL) MarkApplied(ctx context.Context, name, content, note string) error {
+	db, err := p.openConn(ctx)
+	if err != nil {
 		return err
 	}
 
-	tx.Commit()
+	p.ensureHistoryTable(ctx, db)
+
+	query := fmt.Sprintf("INSERT INTO [%s] ([Name],[DateApplied],[Checksum],[Note]) VALUES (@name, GETUTCDATE(), @checksum, @note);", p.HistoryTableName)
+	_, err = db.ExecContext(ctx, query,
+		sql.Named("name", name),
+		sql.Named("checksum", migrations.Checksum(content)),
+		sql.Named("note", sql.NullString{String: note, Valid: note != ""}))
+
+	return err
+}
+
+// MarkUnapplied removes the record of the migration from
+// the migration history table, without rolling it back.
+func (p *MSSQL) MarkUnapplied(ctx context.Context, name string) error {
+	db, err := p.openConn(ctx)
+	if err != nil {
+		return err
+	}
+
+	query := fmt.Sprintf("DELETE FROM [%s] WHERE [Name] = @name;", p.HistoryTableName)
+	_, err = db.ExecContext(ctx, query, sql.Named("name", name))
+
+	return err
+}
+
+// execer is implemented by *sql.Tx and *sql.Conn, which batches are executed on.
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// execBatches executes each of the batches of the migration with the given name, in order.
+func (p *MSSQL) execBatches(ctx context.Context, e execer, d migrations.Direction, name string, batches []string) error {
+	for _, batch := range batches {
+		start := time.Now()
+		_, err := e.ExecContext(ctx, batch)
+		migrations.StatementExecuted(ctx, d, name, batch, start, err)
+
+		if err != nil {
+			return err
+		}
+	}
 
 	return nil
 }
 
+// Lock acquires an exclusive application lock, using sp_getapplock, which is
+// owned by a dedicated connection, held open until Unlock is called.
+func (p *MSSQL) Lock(ctx context.Context, timeout time.Duration) error {
+	db, err := p.openConn(ctx)
+	if err != nil {
+		return err
+	}
+
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+
+	// sp_getapplock waits indefinitely given a timeout of -1.
+	timeoutMS := timeout.Milliseconds()
+	if timeout <= 0 {
+		timeoutMS = -1
+	}
+
+	var result int
+	err = conn.QueryRowContext(ctx,
+		`DECLARE @result INT;
+		EXEC @result = sp_getapplock @Resource = @resource, @LockMode = 'Exclusive', @LockOwner = 'Session', @LockTimeout = @timeout;
+		SELECT @result;`,
+		sql.Named("resource", p.lockResource()),
+		sql.Named("timeout", timeoutMS),
+	).Scan(&result)
+
+	// sp_getapplock returns 0 or 1 if the lock was granted, -1 if
+	// it timed out, and any other negative value on failure.
+	switch {
+	case err == nil && result >= 0:
+		p.lockConn = conn
+		return nil
+	case err == nil && result == -1:
+		err = &migrations.LockError{Timeout: timeout, Holder: p.lockHolder(ctx, conn)}
+	case err == nil:
+		err = fmt.Errorf("sp_getapplock failed with result %d", result)
+	}
+
+	conn.Close()
+
+	return err
+}
+
+// lockHolder returns a description of the session holding the migration lock,
+// or an empty string if it can't be determined, i.e. due to permissions.
+func (p *MSSQL) lockHolder(ctx context.Context, conn *sql.Conn) string {
+	var holder string
+	conn.QueryRowContext(ctx,
+		`SELECT TOP 1 CONCAT('session ', l.[request_session_id], ' (', s.[login_name], '@', s.[host_name], ')')
+		FROM sys.dm_tran_locks l
+		INNER JOIN sys.dm_exec_sessions s ON s.[session_id] = l.[request_session_id]
+		WHERE l.[resource_type] = 'APPLICATION' AND l.[request_status] = 'GRANT'
+			AND l.[resource_description] LIKE '%' + @resource + '%';`,
+		sql.Named("resource", p.lockResource()),
+	).Scan(&holder)
+
+	return holder
+}
+
+// Unlock releases the application lock acquired by Lock, returning its connection to the pool.
+func (p *MSSQL) Unlock(ctx context.Context) error {
+	if p.lockConn == nil {
+		return nil
+	}
+
+	defer func() {
+		p.lockConn.Close()
+		p.lockConn = nil
+	}()
+
+	_, err := p.lockConn.ExecContext(ctx,
+		"EXEC sp_releaseapplock @Resource = @resource, @LockOwner = 'Session';",
+		sql.Named("resource", p.lockResource()))
+
+	return err
+}
+
+// lockResource returns the name of the application lock, which is
+// specific to the history table, as app locks are database scoped.
+func (p *MSSQL) lockResource() string {
+	return "migrations:" + p.HistoryTableName
+}
+
+// Template returns the initial content of a migration file, created by the create command.
+func (p *MSSQL) Template(name string, d migrations.Direction) string {
+	return fmt.Sprintf("-- %s (%s)\n"+
+		"-- Batches are separated by GO, and executed in a transaction.\n\n", name, d)
+}
+
+// openConn returns the connection pool, opening it on first use.
 func (p *MSSQL) openConn(ctx context.Context) (*sql.DB, error) {
-	db, _ := sql.Open("sqlserver", p.ConnectionString)
-	err := db.PingContext(ctx)
+	if p.db != nil {
+		return p.db, nil
+	}
+
+	if p.ConnectionString == "" {
+		return nil, migrations.ErrNoConnectionString
+	}
+
+	db, err := sql.Open("sqlserver", p.ConnectionString)
+	if err != nil {
+		return nil, err
+	}
+
+	db.SetMaxOpenConns(p.MaxOpenConns)
+	if p.MaxIdleConns > 0 {
+		db.SetMaxIdleConns(p.MaxIdleConns)
+	}
+
+	err = db.PingContext(ctx)
 	if err != nil {
+		db.Close()
 		return nil, err
 	}
 
+	p.db = db
+
 	return db, nil
 }
+
+// Close releases the migration lock's connection, if held,
+// and closes the connection pool. The provider can be reused,
+// as the pool is reopened when next needed.
+func (p *MSSQL) Close() error {
+	if p.lockConn != nil {
+		p.lockConn.Close()
+		p.lockConn = nil
+	}
+
+	if p.db == nil {
+		return nil
+	}
+
+	err := p.db.Close()
+	p.db = nil
+	p.historyTableReady = false
+
+	return err
+}
@@ -0,0 +1,232 @@
+package mssql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/reecerussell/migrations"
+)
+
+// newMockProvider returns an MSSQL provider, using a mock connection pool.
+func newMockProvider(t *testing.T) (*MSSQL, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+
+	t.Cleanup(func() {
+		db.Close()
+	})
+
+	return &MSSQL{HistoryTableName: defaultHistoryTableName, db: db}, mock
+}
+
+func expectHistoryTable(mock sqlmock.Sqlmock) {
+	mock.ExpectExec("IF NOT EXISTS").WillReturnResult(sqlmock.NewResult(0, 0))
+}
+
+const (
+	applyHistory    = "INSERT INTO [__MigrationHistory] ([Name],[DateApplied],[Checksum]) VALUES (@name, GETUTCDATE(), @checksum);"
+	rollbackHistory = "DELETE FROM [__MigrationHistory] WHERE [Name] = @name;"
+
+	// batchContent uses the GO separator, so is run as three batches, the last twice.
+	batchContent = "CREATE TABLE audit (id INT);\nGO\nCREATE VIEW audit_view AS SELECT id FROM audit;\nGO\nINSERT INTO audit VALUES (1);\nGO 2\n"
+)
+
+func TestTransaction(t *testing.T) {
+	testError := errors.New("an error occurred")
+
+	tests := []struct {
+		name      string
+		rollback  bool
+		withoutTx bool
+		cancelled bool
+		content   string
+		expect    func(mock sqlmock.Sqlmock)
+		phase     migrations.TxPhase
+		err       error
+	}{
+		{
+			name:    "Apply Given Batches Runs Each Batch In Transaction",
+			content: batchContent,
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta("CREATE TABLE audit (id INT);")).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec(regexp.QuoteMeta("CREATE VIEW audit_view AS SELECT id FROM audit;")).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec(regexp.QuoteMeta("INSERT INTO audit VALUES (1);")).WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec(regexp.QuoteMeta("INSERT INTO audit VALUES (1);")).WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec(regexp.QuoteMeta(applyHistory)).
+					WithArgs(sql.Named("name", "MyMigration"), sql.Named("checksum", migrations.Checksum(batchContent))).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name:    "Apply Where Begin Fails",
+			content: "UPDATE users SET active = 1;",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin().WillReturnError(testError)
+			},
+			phase: migrations.TxBegin,
+			err:   testError,
+		},
+		{
+			name:    "Apply Where Second Batch Fails Rolls Back",
+			content: batchContent,
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta("CREATE TABLE audit (id INT);")).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec(regexp.QuoteMeta("CREATE VIEW audit_view AS SELECT id FROM audit;")).WillReturnError(testError)
+				mock.ExpectRollback()
+			},
+			phase: migrations.TxExec,
+			err:   testError,
+		},
+		{
+			name:    "Apply Where History Fails Rolls Back",
+			content: "UPDATE users SET active = 1;",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta("UPDATE users SET active = 1;")).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(regexp.QuoteMeta(applyHistory)).WillReturnError(testError)
+				mock.ExpectRollback()
+			},
+			phase: migrations.TxHistory,
+			err:   testError,
+		},
+		{
+			name:    "Apply Where Commit Fails",
+			content: "UPDATE users SET active = 1;",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta("UPDATE users SET active = 1;")).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(regexp.QuoteMeta(applyHistory)).WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit().WillReturnError(testError)
+			},
+			phase: migrations.TxCommit,
+			err:   testError,
+		},
+		{
+			name:     "Rollback Removes History In Transaction",
+			rollback: true,
+			content:  "UPDATE users SET active = 0;",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta("UPDATE users SET active = 0;")).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(regexp.QuoteMeta(rollbackHistory)).
+					WithArgs(sql.Named("name", "MyMigration")).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name:     "Rollback Where History Fails Rolls Back",
+			rollback: true,
+			content:  "UPDATE users SET active = 0;",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta("UPDATE users SET active = 0;")).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(regexp.QuoteMeta(rollbackHistory)).WillReturnError(testError)
+				mock.ExpectRollback()
+			},
+			phase: migrations.TxHistory,
+			err:   testError,
+		},
+		{
+			name:      "Apply Without Transaction Records History After Batches",
+			withoutTx: true,
+			content:   batchContent,
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(regexp.QuoteMeta("CREATE TABLE audit (id INT);")).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec(regexp.QuoteMeta("CREATE VIEW audit_view AS SELECT id FROM audit;")).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec(regexp.QuoteMeta("INSERT INTO audit VALUES (1);")).WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec(regexp.QuoteMeta("INSERT INTO audit VALUES (1);")).WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec(regexp.QuoteMeta(applyHistory)).
+					WithArgs(sql.Named("name", "MyMigration"), sql.Named("checksum", migrations.Checksum(batchContent))).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+			},
+		},
+		{
+			name:      "Apply Without Transaction Where Batch Fails Does Not Record History",
+			withoutTx: true,
+			content:   batchContent,
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(regexp.QuoteMeta("CREATE TABLE audit (id INT);")).WillReturnError(testError)
+			},
+			phase: migrations.TxExec,
+			err:   testError,
+		},
+		{
+			name:      "Rollback Without Transaction Where Connection Fails",
+			rollback:  true,
+			withoutTx: true,
+			cancelled: true,
+			content:   "UPDATE users SET active = 0;",
+			expect:    func(mock sqlmock.Sqlmock) {},
+			phase:     migrations.TxBegin,
+			err:       context.Canceled,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			p, mock := newMockProvider(t)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if test.withoutTx {
+				ctx = migrations.ContextWithoutTransaction(ctx)
+			}
+
+			if !test.rollback {
+				expectHistoryTable(mock)
+			}
+			test.expect(mock)
+
+			if test.cancelled {
+				cancel()
+			}
+
+			var err error
+			if test.rollback {
+				err = p.Rollback(ctx, "MyMigration", test.content)
+			} else {
+				err = p.Apply(ctx, "MyMigration", test.content)
+			}
+
+			if test.phase == "" {
+				assert.NoError(t, err)
+			} else {
+				var txErr *migrations.TxError
+				if assert.True(t, errors.As(err, &txErr)) {
+					assert.Equal(t, "MyMigration", txErr.Migration)
+					assert.Equal(t, test.phase, txErr.Phase)
+				}
+				assert.True(t, errors.Is(err, test.err))
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestTransaction_GivenInvalidBatchCount_DoesNotBeginTransaction(t *testing.T) {
+	p, mock := newMockProvider(t)
+
+	expectHistoryTable(mock)
+
+	err := p.Apply(context.Background(), "MyMigration", "SELECT 1;\nGO 0\n")
+	assert.EqualError(t, err, "invalid batch count: GO 0")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTransaction_GivenUnknownIsolationLevel_ReturnsError(t *testing.T) {
+	p, err := New(migrations.ConfigMap{"connectionString": "test", "isolationLevel": "eventual"})
+	assert.Nil(t, p)
+	assert.EqualError(t, err, "unknown isolation level: eventual")
+}
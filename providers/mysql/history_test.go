@@ -0,0 +1,45 @@
+package mysql
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnsureHistoryTable_CalledTwice_ChecksTableOnce(t *testing.T) {
+	p, mock := newMockProvider(t)
+
+	expectHistoryTable(mock)
+
+	p.ensureHistoryTable(context.Background(), p.db)
+	p.ensureHistoryTable(context.Background(), p.db)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestEnsureHistoryTable_WithoutAddedColumns_AddsColumnsOnce(t *testing.T) {
+	p, mock := newMockProvider(t)
+
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT COUNT").WithArgs("__migration_history", "checksum").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectExec("ALTER TABLE `__migration_history` ADD COLUMN `checksum`").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT COUNT").WithArgs("__migration_history", "note").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectExec("ALTER TABLE `__migration_history` ADD COLUMN `note`").WillReturnResult(sqlmock.NewResult(0, 0))
+
+	p.ensureHistoryTable(context.Background(), p.db)
+	p.ensureHistoryTable(context.Background(), p.db)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestEnsureHistoryTable_WhereCheckFails_ChecksAgain(t *testing.T) {
+	p, mock := newMockProvider(t)
+
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS").WillReturnError(errors.New("an error occurred"))
+	expectHistoryTable(mock)
+
+	p.ensureHistoryTable(context.Background(), p.db)
+	p.ensureHistoryTable(context.Background(), p.db)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
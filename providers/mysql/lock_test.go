@@ -0,0 +1,35 @@
+package mysql
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLock_GivenTimeout_PassesSecondsToGetLock(t *testing.T) {
+	tests := []struct {
+		timeout time.Duration
+		seconds int
+	}{
+		{timeout: 1500 * time.Millisecond, seconds: 2},
+		{timeout: 0, seconds: -1},
+		{timeout: -time.Second, seconds: -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.timeout.String(), func(t *testing.T) {
+			p, mock := newMockProvider(t)
+
+			mock.ExpectQuery(`SELECT GET_LOCK\(CONCAT\('migrations:', SHA1\(`).
+				WithArgs(defaultHistoryTableName, tt.seconds).
+				WillReturnRows(sqlmock.NewRows([]string{"result"}).AddRow(1))
+
+			err := p.Lock(context.Background(), tt.timeout)
+			assert.NoError(t, err)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
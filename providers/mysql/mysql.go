@@ -3,9 +3,10 @@ package mysql
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
-	"os"
-	"strings"
+	"math"
+	"time"
 
 	"github.com/reecerussell/migrations"
 	"github.com/reecerussell/migrations/providers"
@@ -14,7 +15,26 @@ import (
 	_ "github.com/go-sql-driver/mysql"
 )
 
-const defaultHistoryTableName = "__migration_history"
+const (
+	defaultHistoryTableName = "__migration_history"
+
+	// lockName is an expression returning the name of the migration lock.
+	// As locks are server wide, it's specific to the database and history table,
+	// which are hashed, as lock names are limited to 64 characters.
+	lockName = "CONCAT('migrations:', SHA1(CONCAT(IFNULL(DATABASE(), ''), '.', ?)))"
+)
+
+// addedHistoryColumns are the columns added to the history table since it was
+// first created, which are added to tables created by previous versions.
+var addedHistoryColumns = []struct{ name, definition string }{
+	{name: "checksum", definition: "VARCHAR(64) NULL"},
+	{name: "note", definition: "TEXT NULL"},
+}
+
+// errMaxOpenConns is returned by New if maxOpenConns is 1, as the migration
+// lock holds a connection from the pool for the whole run, which would leave
+// none to run the migrations on.
+var errMaxOpenConns = errors.New("maxOpenConns must be at least 2, as the migration lock holds a connection")
 
 func init() {
 	providers.Add("mysql", New)
@@ -24,25 +44,73 @@ func init() {
 type MySQL struct {
 	ConnectionString string
 	HistoryTableName string
-	PrintStatements  bool
+
+	// IsolationLevel is the isolation level of the transactions migrations
+	// are run in. Defaults to sql.LevelReadUncommitted.
+	IsolationLevel sql.IsolationLevel
+
+	// MaxOpenConns and MaxIdleConns configure the connection pool, which is
+	// opened on first use and reused until Close is called. Zero values
+	// leave the database/sql defaults in place.
+	MaxOpenConns int
+	MaxIdleConns int
+
+	// db is the connection pool, and lockConn holds the
+	// connection, taken from db, which owns the migration lock.
+	db       *sql.DB
+	lockConn *sql.Conn
+
+	// historyTableReady is set once the history table has been
+	// ensured, so it's only checked once per connection pool.
+	historyTableReady bool
 }
 
 // New returns a new instance of MySQL. Implementing providers.ConstructorFunc,
-// New takes a migrations.ConfigMap, which is used to populate HistoryTableName.
-func New(conf migrations.ConfigMap) migrations.Provider {
-	historyTableName := defaultHistoryTableName
-	if v, _ := conf.String("historyTableName"); v != "" {
-		historyTableName = v
+// New takes a migrations.ConfigMap, which is used to populate ConnectionString,
+// resolved using migrations.ResolveConnectionString, falling back to CONNECTION_STRING,
+// as given, HistoryTableName and IsolationLevel, as well as the connection pool's
+// size. An error is returned if the ConfigMap is invalid, or neither connection
+// string is set.
+func New(conf migrations.ConfigMap) (migrations.Provider, error) {
+	p := &MySQL{}
+	connectionString, fromConfig, err := conf.RawConnectionString()
+	if err != nil {
+		return nil, err
+	}
+	if connectionString == "" {
+		return nil, migrations.ErrNoConnectionString
+	}
+	if fromConfig {
+		connectionString, err = migrations.ResolveConnectionString(connectionString)
+		if err != nil {
+			return nil, err
+		}
+	}
+	p.ConnectionString = connectionString
+	p.HistoryTableName, err = conf.GetString("historyTableName", defaultHistoryTableName)
+	if err != nil {
+		return nil, err
+	}
+	isolationLevel, err := conf.GetString("isolationLevel", sql.LevelReadUncommitted.String())
+	if err != nil {
+		return nil, err
 	}
-	printStatements := false
-	if v, _ := conf.String("printStatements"); v == "true" {
-		printStatements = true
+	p.IsolationLevel, err = migrations.ParseIsolationLevel(isolationLevel)
+	if err != nil {
+		return nil, err
 	}
-	return &MySQL{
-		ConnectionString: os.Getenv("CONNECTION_STRING"),
-		HistoryTableName: historyTableName,
-		PrintStatements:  printStatements,
+	p.MaxOpenConns, err = conf.GetInt("maxOpenConns", 0)
+	if err != nil {
+		return nil, err
+	}
+	if p.MaxOpenConns == 1 {
+		return nil, errMaxOpenConns
 	}
+	p.MaxIdleConns, err = conf.GetInt("maxIdleConns", 0)
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
 }
 
 // GetAppliedMigrations queries the migration history table for all applied migrations.
@@ -52,115 +120,288 @@ func (p *MySQL) GetAppliedMigrations(ctx context.Context) ([]*migrations.Migrati
 		return nil, err
 	}
 	p.ensureHistoryTable(ctx, db)
-	query := fmt.Sprintf("SELECT `id`, `name`, `date_applied` FROM `%s`;", p.HistoryTableName)
+	query := fmt.Sprintf("SELECT `id`, `name`, `date_applied`, `checksum` FROM `%s`;", p.HistoryTableName)
 	rows, err := db.QueryContext(ctx, query)
 	if err != nil {
 		return nil, err
 	}
+	defer rows.Close()
 	var appliedMigrations []*migrations.Migration
 	for rows.Next() {
 		var m migrations.Migration
+		var checksum sql.NullString
 		err := rows.Scan(
 			&m.ID,
 			&m.Name,
 			&m.DateApplied,
+			&checksum,
 		)
 		if err != nil {
 			return nil, err
 		}
+		m.Checksum = checksum.String
 		appliedMigrations = append(appliedMigrations, &m)
 	}
 
 	return appliedMigrations, nil
 }
 
-// ensureHistoryTable ensures the table with the name historyTableName exists.
+// ensureHistoryTable ensures the table with the name historyTableName exists,
+// adding the checksum column to tables created by previous versions.
 // Should be provided a valid instance of *sql.DB.
 func (p *MySQL) ensureHistoryTable(ctx context.Context, db *sql.DB) {
+	if p.historyTableReady {
+		return
+	}
 	query := fmt.Sprintf(
 		"CREATE TABLE IF NOT EXISTS `%s` ("+
 			"`id` INT NOT NULL AUTO_INCREMENT PRIMARY KEY,"+
 			"`name` VARCHAR(255) NOT NULL,"+
-			"`date_applied` DATETIME NOT NULL"+
+			"`date_applied` DATETIME NOT NULL,"+
+			"`checksum` VARCHAR(64) NULL,"+
+			"`note` TEXT NULL"+
 			");",
 		p.HistoryTableName,
 	)
 
 	// This should never return an error, as it's given a valid
 	// *sql.DB instance, with an open connection. Plus the query
-	// is valid.
-	db.ExecContext(ctx, query)
+	// is valid. If it does, the table is ensured again next time.
+	if _, err := db.ExecContext(ctx, query); err != nil {
+		return
+	}
+
+	for _, column := range addedHistoryColumns {
+		var count int
+		row := db.QueryRowContext(ctx,
+			"SELECT COUNT(*) FROM `information_schema`.`COLUMNS` "+
+				"WHERE `TABLE_SCHEMA` = DATABASE() AND `TABLE_NAME` = ? AND `COLUMN_NAME` = ?;",
+			p.HistoryTableName, column.name)
+		if err := row.Scan(&count); err != nil {
+			return
+		}
+		if count == 0 {
+			query = fmt.Sprintf("ALTER TABLE `%s` ADD COLUMN `%s` %s;", p.HistoryTableName, column.name, column.definition)
+			if _, err := db.ExecContext(ctx, query); err != nil {
+				return
+			}
+		}
+	}
+	p.historyTableReady = true
 }
 
 // Apply applies the migration, m, to the database, as well as
-// adding a record to the migration history table.
+// adding a record to the migration history table, including
+// the checksum of content.
 func (p *MySQL) Apply(ctx context.Context, name, content string) error {
 	db, err := p.openConn(ctx)
 	if err != nil {
 		return err
 	}
-	tx, _ := db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelReadUncommitted})
-	statements := strings.Split(content, ";")
-	for _, statement := range statements {
-		if strings.TrimSpace(statement) == "" {
-			continue
+	p.ensureHistoryTable(ctx, db)
+	query := fmt.Sprintf("INSERT INTO `%s` (`name`,`date_applied`,`checksum`) VALUES (?, UTC_TIMESTAMP(), ?);", p.HistoryTableName)
+	return p.run(ctx, db, migrations.Up, name, content, query, name, migrations.Checksum(content))
+}
+
+// Rollback rolls back the migration, m, then removed the
+// record from the migration history table.
+func (p *MySQL) Rollback(ctx context.Context, name, content string) error {
+	db, err := p.openConn(ctx)
+	if err != nil {
+		return err
+	}
+	query := fmt.Sprintf("DELETE FROM `%s` WHERE `name` = ?;", p.HistoryTableName)
+	return p.run(ctx, db, migrations.Down, name, content, query, name)
+}
+
+// run executes the statements of the migration with the given name, then updates the
+// migration history, using the query and its args, in a transaction. If ctx says
+// the migration isn't run in a transaction, both are executed on a single connection,
+// and the history is only updated once all of the statements have succeeded.
+func (p *MySQL) run(ctx context.Context, db *sql.DB, d migrations.Direction, name, content, query string, args ...interface{}) error {
+	if !migrations.TransactionFromContext(ctx) {
+		conn, err := db.Conn(ctx)
+		if err != nil {
+			return &migrations.TxError{Migration: name, Phase: migrations.TxBegin, Err: err}
 		}
-		if p.PrintStatements {
-			fmt.Printf("Executing the following statement:\n%s\n", statement)
+		defer conn.Close()
+		err = p.execStatements(ctx, conn, d, name, content)
+		if err != nil {
+			return &migrations.TxError{Migration: name, Phase: migrations.TxExec, Err: err}
 		}
-		_, err = tx.ExecContext(ctx, statement)
+		_, err = conn.ExecContext(ctx, query, args...)
 		if err != nil {
-			tx.Rollback()
-			return err
+			return &migrations.TxError{Migration: name, Phase: migrations.TxHistory, Err: err}
 		}
+		return nil
 	}
-	query := fmt.Sprintf("INSERT INTO `%s` (`name`,`date_applied`) VALUES (?, UTC_TIMESTAMP());", p.HistoryTableName)
-	_, err = tx.ExecContext(ctx, query, name)
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{Isolation: p.IsolationLevel})
+	if err != nil {
+		return &migrations.TxError{Migration: name, Phase: migrations.TxBegin, Err: err}
+	}
+	err = p.execStatements(ctx, tx, d, name, content)
 	if err != nil {
 		tx.Rollback()
-		return err
+		return &migrations.TxError{Migration: name, Phase: migrations.TxExec, Err: err}
+	}
+	_, err = tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		tx.Rollback()
+		return &migrations.TxError{Migration: name, Phase: migrations.TxHistory, Err: err}
+	}
+	err = tx.Commit()
+	if err != nil {
+		return &migrations.TxError{Migration: name, Phase: migrations.TxCommit, Err: err}
 	}
-	tx.Commit()
 	return nil
 }
 
-// Rollback rolls back the migration, m, then removed the
-// record from the migration history table.
-func (p *MySQL) Rollback(ctx context.Context, name, content string) error {
+// execer is implemented by *sql.Tx and *sql.Conn, which statements are executed on.
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// execStatements executes each of the statements in the content of
+// the migration with the given name, in order, as split by splitStatements.
+func (p *MySQL) execStatements(ctx context.Context, e execer, d migrations.Direction, name, content string) error {
+	for _, statement := range splitStatements(content) {
+		start := time.Now()
+		_, err := e.ExecContext(ctx, statement)
+		migrations.StatementExecuted(ctx, d, name, statement, start, err)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MarkApplied adds a record of the migration to the migration
+// history table, including the checksum of content and the note, if any,
+// without applying it.
+func (p *MySQL) MarkApplied(ctx context.Context, name, content, note string) error {
 	db, err := p.openConn(ctx)
 	if err != nil {
 		return err
 	}
-	tx, _ := db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelReadUncommitted})
-	statements := strings.Split(content, ";")
-	for _, statement := range statements {
-		if strings.TrimSpace(statement) == "" {
-			continue
-		}
-		if p.PrintStatements {
-			fmt.Printf("Executing the following statement:\n%s\n", statement)
-		}
-		_, err = tx.ExecContext(ctx, statement)
-		if err != nil {
-			tx.Rollback()
-			return err
-		}
+	p.ensureHistoryTable(ctx, db)
+	query := fmt.Sprintf("INSERT INTO `%s` (`name`,`date_applied`,`checksum`,`note`) VALUES (?, UTC_TIMESTAMP(), ?, ?);", p.HistoryTableName)
+	_, err = db.ExecContext(ctx, query, name, migrations.Checksum(content), sql.NullString{String: note, Valid: note != ""})
+	return err
+}
+
+// MarkUnapplied removes the record of the migration from
+// the migration history table, without rolling it back.
+func (p *MySQL) MarkUnapplied(ctx context.Context, name string) error {
+	db, err := p.openConn(ctx)
+	if err != nil {
+		return err
 	}
 	query := fmt.Sprintf("DELETE FROM `%s` WHERE `name` = ?;", p.HistoryTableName)
-	_, err = tx.ExecContext(ctx, query, name)
+	_, err = db.ExecContext(ctx, query, name)
+	return err
+}
+
+// Lock acquires an exclusive named lock, using GET_LOCK, which is owned
+// by a dedicated connection, taken from the pool, held until Unlock is called.
+func (p *MySQL) Lock(ctx context.Context, timeout time.Duration) error {
+	db, err := p.openConn(ctx)
 	if err != nil {
-		tx.Rollback()
 		return err
 	}
-	tx.Commit()
-	return nil
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	// GET_LOCK returns 1 if the lock was granted, 0 if it timed out, and NULL on error.
+	var result sql.NullInt64
+	// GET_LOCK waits indefinitely given a negative timeout.
+	seconds := int(math.Ceil(timeout.Seconds()))
+	if timeout <= 0 {
+		seconds = -1
+	}
+	err = conn.QueryRowContext(ctx, "SELECT GET_LOCK("+lockName+", ?);", p.HistoryTableName, seconds).Scan(&result)
+	switch {
+	case err == nil && result.Int64 == 1:
+		p.lockConn = conn
+		return nil
+	case err == nil && result.Valid:
+		err = &migrations.LockError{Timeout: timeout, Holder: p.lockHolder(ctx, conn)}
+	case err == nil:
+		err = fmt.Errorf("failed to acquire migration lock")
+	}
+	conn.Close()
+	return err
 }
 
+// lockHolder returns a description of the connection holding the migration lock,
+// or an empty string if it can't be determined, i.e. if it has since been released.
+func (p *MySQL) lockHolder(ctx context.Context, conn *sql.Conn) string {
+	var holder string
+	conn.QueryRowContext(ctx,
+		"SELECT CONCAT('connection ', `ID`, ' (', `USER`, '@', `HOST`, ')') "+
+			"FROM `information_schema`.`PROCESSLIST` WHERE `ID` = IS_USED_LOCK("+lockName+");",
+		p.HistoryTableName).Scan(&holder)
+	return holder
+}
+
+// Unlock releases the named lock acquired by Lock, returning its connection to the pool.
+func (p *MySQL) Unlock(ctx context.Context) error {
+	if p.lockConn == nil {
+		return nil
+	}
+	defer func() {
+		p.lockConn.Close()
+		p.lockConn = nil
+	}()
+	_, err := p.lockConn.ExecContext(ctx, "SELECT RELEASE_LOCK("+lockName+");", p.HistoryTableName)
+	return err
+}
+
+// Template returns the initial content of a migration file, created by the create command.
+func (p *MySQL) Template(name string, d migrations.Direction) string {
+	return fmt.Sprintf("-- %s (%s)\n"+
+		"-- Statements must be terminated with a semicolon. Note, MySQL implicitly\n"+
+		"-- commits DDL statements, so they can't be rolled back if the migration fails.\n\n", name, d)
+}
+
+// openConn returns the connection pool, opening it on first use.
 func (p *MySQL) openConn(ctx context.Context) (*sql.DB, error) {
-	db, _ := sql.Open("mysql", p.ConnectionString)
-	err := db.PingContext(ctx)
+	if p.db != nil {
+		return p.db, nil
+	}
+	if p.ConnectionString == "" {
+		return nil, migrations.ErrNoConnectionString
+	}
+	db, err := sql.Open("mysql", p.ConnectionString)
 	if err != nil {
 		return nil, err
 	}
+	db.SetMaxOpenConns(p.MaxOpenConns)
+	if p.MaxIdleConns > 0 {
+		db.SetMaxIdleConns(p.MaxIdleConns)
+	}
+	err = db.PingContext(ctx)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	p.db = db
 	return db, nil
 }
+
+// Close releases the migration lock's connection, if held,
+// and closes the connection pool. The provider can be reused,
+// as the pool is reopened when next needed.
+func (p *MySQL) Close() error {
+	if p.lockConn != nil {
+		p.lockConn.Close()
+		p.lockConn = nil
+	}
+	if p.db == nil {
+		return nil
+	}
+	err := p.db.Close()
+	p.db = nil
+	p.historyTableReady = false
+	return err
+}
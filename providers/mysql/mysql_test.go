@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
@@ -25,11 +26,54 @@ func execute(db *sql.DB, queryf string, inlineArgs ...interface{}) {
 
 func TestNew(t *testing.T) {
 	cnf := migrations.ConfigMap{
+		"connectionString": "user:password@tcp(localhost)/db?parseTime=true",
 		"historyTableName": "MyMigrationsTable",
+		"isolationLevel":   "serializable",
+		"maxOpenConns":     5,
+		"maxIdleConns":     2,
 	}
-	p := mysql.New(cnf).(*mysql.MySQL)
+	provider, err := mysql.New(cnf)
+	assert.NoError(t, err)
+
+	p := provider.(*mysql.MySQL)
 
+	assert.Equal(t, "user:password@tcp(localhost)/db?parseTime=true", p.ConnectionString)
 	assert.Equal(t, "MyMigrationsTable", p.HistoryTableName)
+	assert.Equal(t, sql.LevelSerializable, p.IsolationLevel)
+	assert.Equal(t, 5, p.MaxOpenConns)
+	assert.Equal(t, 2, p.MaxIdleConns)
+}
+
+func TestNew_GivenMaxOpenConnsOfOne_ReturnsError(t *testing.T) {
+	provider, err := mysql.New(migrations.ConfigMap{"connectionString": "test", "maxOpenConns": 1})
+	assert.Nil(t, provider)
+	assert.EqualError(t, err, "maxOpenConns must be at least 2, as the migration lock holds a connection")
+}
+
+func TestNew_GivenUnsetVariable_ReturnsError(t *testing.T) {
+	provider, err := mysql.New(migrations.ConfigMap{"connectionString": "${TEST_DB_URL_UNSET}"})
+	assert.Nil(t, provider)
+	assert.EqualError(t, err, "connectionString: environment variable TEST_DB_URL_UNSET is not set")
+}
+
+func TestNew_WithoutConnectionString_UsesEnvironmentAsGiven(t *testing.T) {
+	os.Setenv("CONNECTION_STRING", "${TEST_DB_URL_UNSET}")
+	t.Cleanup(func() {
+		os.Unsetenv("CONNECTION_STRING")
+	})
+
+	provider, err := mysql.New(nil)
+	assert.NoError(t, err)
+
+	p := provider.(*mysql.MySQL)
+	assert.Equal(t, "${TEST_DB_URL_UNSET}", p.ConnectionString)
+}
+
+func TestGetAppliedMigrations_WithoutConnectionString_ReturnsError(t *testing.T) {
+	p := &mysql.MySQL{}
+
+	_, err := p.GetAppliedMigrations(context.Background())
+	assert.Equal(t, migrations.ErrNoConnectionString, err)
 }
 
 func TestGetAppliedMigrations_HavingOneAppliedMigration_ReturnsMigrationSuccessfully(t *testing.T) {
@@ -283,3 +327,37 @@ func TestRollback_GivenMigrationWithInvalidSQL_ReturnsError(t *testing.T) {
 	err := p.Rollback(context.TODO(), "CreateTable", `DROP TABLE TestRollback'`) // invalid sql
 	assert.NotNil(t, err)
 }
+
+func TestLock_WhereLockIsHeld_ReturnsLockError(t *testing.T) {
+	p1 := &mysql.MySQL{
+		ConnectionString: testConnectionString,
+		HistoryTableName: "__MigrationHistory",
+	}
+	p2 := &mysql.MySQL{
+		ConnectionString: testConnectionString,
+		HistoryTableName: "__MigrationHistory",
+	}
+
+	err := p1.Lock(context.TODO(), time.Second)
+	assert.NoError(t, err)
+
+	err = p2.Lock(context.TODO(), time.Second)
+	lockErr, ok := err.(*migrations.LockError)
+	assert.True(t, ok)
+	assert.Equal(t, time.Second, lockErr.Timeout)
+
+	err = p1.Unlock(context.TODO())
+	assert.NoError(t, err)
+
+	err = p2.Lock(context.TODO(), time.Second)
+	assert.NoError(t, err)
+
+	err = p2.Unlock(context.TODO())
+	assert.NoError(t, err)
+}
+
+func TestTemplate(t *testing.T) {
+	p := &mysql.MySQL{}
+	assert.Contains(t, p.Template("CreateTable", migrations.Up), "-- CreateTable (up)\n")
+	assert.Contains(t, p.Template("CreateTable", migrations.Down), "-- CreateTable (down)\n")
+}
@@ -0,0 +1,129 @@
+package mysql
+
+import (
+	"strings"
+)
+
+const (
+	defaultDelimiter   = ";"
+	delimiterDirective = "DELIMITER"
+)
+
+// splitStatements splits the content of a migration into its individual
+// statements, as MySQL doesn't execute multiple statements in a single query,
+// by default. Statements are separated by the delimiter, which is ignored in
+// string literals, quoted identifiers and comments, and can be changed using
+// the DELIMITER directive, as supported by the mysql client, i.e. to create
+// stored procedures and triggers. Statements are trimmed of surrounding
+// whitespace, and any consisting only of comments are omitted.
+func splitStatements(content string) []string {
+	var statements []string
+	delimiter := defaultDelimiter
+	start := 0
+	// hasContent is true if the current statement has
+	// anything other than whitespace and comments.
+	hasContent := false
+	add := func(end int) {
+		if hasContent {
+			statements = append(statements, strings.TrimSpace(content[start:end]))
+		}
+		hasContent = false
+	}
+	for i := 0; i < len(content); {
+		c := content[i]
+		switch {
+		case c == '\'' || c == '"' || c == '`':
+			i = skipQuoted(content, i)
+			hasContent = true
+		case c == '#' || isDashComment(content, i):
+			i = skipLine(content, i)
+		case strings.HasPrefix(content[i:], "/*"):
+			// Executable comments, i.e. /*!50003 ... */, are run by MySQL.
+			if strings.HasPrefix(content[i:], "/*!") {
+				hasContent = true
+			}
+			i = skipBlockComment(content, i)
+		case !hasContent && isDelimiterDirective(content, i):
+			end := skipLine(content, i)
+			delimiter = strings.Fields(content[i+len(delimiterDirective) : end])[0]
+			i, start = end, end
+		case strings.HasPrefix(content[i:], delimiter):
+			add(i)
+			i += len(delimiter)
+			start = i
+		default:
+			if !isSpace(c) {
+				hasContent = true
+			}
+			i++
+		}
+	}
+	add(len(content))
+	return statements
+}
+
+// skipQuoted returns the index following the string literal, or quoted
+// identifier, starting at i. Quotes are escaped by doubling them, and,
+// other than in identifiers, by a backslash.
+func skipQuoted(s string, i int) int {
+	quote := s[i]
+	for i++; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			if quote != '`' {
+				i++
+			}
+		case quote:
+			if i+1 < len(s) && s[i+1] == quote {
+				i++
+				continue
+			}
+			return i + 1
+		}
+	}
+	return len(s)
+}
+
+// isDashComment determines whether a "-- " comment starts at i. MySQL
+// requires the dashes to be followed by whitespace, or the end of the input.
+func isDashComment(s string, i int) bool {
+	if !strings.HasPrefix(s[i:], "--") {
+		return false
+	}
+	return i+2 == len(s) || isSpace(s[i+2])
+}
+
+// skipLine returns the index following the end of the line containing i.
+func skipLine(s string, i int) int {
+	n := strings.IndexByte(s[i:], '\n')
+	if n < 0 {
+		return len(s)
+	}
+	return i + n + 1
+}
+
+// skipBlockComment returns the index following the block comment starting at i.
+func skipBlockComment(s string, i int) int {
+	n := strings.Index(s[i+2:], "*/")
+	if n < 0 {
+		return len(s)
+	}
+	return i + 2 + n + 2
+}
+
+// isDelimiterDirective determines whether a DELIMITER directive, with
+// a delimiter, starts at i. The directive is case insensitive.
+func isDelimiterDirective(s string, i int) bool {
+	end := i + len(delimiterDirective)
+	if end >= len(s) || !strings.EqualFold(s[i:end], delimiterDirective) {
+		return false
+	}
+	if s[end] != ' ' && s[end] != '\t' {
+		return false
+	}
+	return len(strings.Fields(s[end:skipLine(s, end)])) > 0
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f' || c == '\v'
+}
@@ -0,0 +1,27 @@
+//go:build go1.18
+// +build go1.18
+
+package mysql
+
+import (
+	"strings"
+	"testing"
+)
+
+func FuzzSplitStatements(f *testing.F) {
+	for _, test := range splitStatementsTests {
+		f.Add(test.content)
+	}
+
+	f.Fuzz(func(t *testing.T, content string) {
+		for _, statement := range splitStatements(content) {
+			if statement == "" || statement != strings.TrimSpace(statement) {
+				t.Fatalf("statement %q is not trimmed, or is empty", statement)
+			}
+
+			if !strings.Contains(content, statement) {
+				t.Fatalf("statement %q is not part of the content", statement)
+			}
+		}
+	})
+}
@@ -0,0 +1,132 @@
+package mysql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var splitStatementsTests = []struct {
+	name     string
+	content  string
+	expected []string
+}{
+	{
+		name:     "Empty",
+		content:  "",
+		expected: nil,
+	},
+	{
+		name:     "Single statement without delimiter",
+		content:  "SELECT 1",
+		expected: []string{"SELECT 1"},
+	},
+	{
+		name:     "Multiple statements",
+		content:  "CREATE TABLE a (id INT);\nCREATE TABLE b (id INT);\n",
+		expected: []string{"CREATE TABLE a (id INT)", "CREATE TABLE b (id INT)"},
+	},
+	{
+		name:     "Empty statements",
+		content:  ";;\n SELECT 1; ;",
+		expected: []string{"SELECT 1"},
+	},
+	{
+		name:     "Delimiter in single quoted string",
+		content:  "INSERT INTO a VALUES ('a;b'); SELECT 1;",
+		expected: []string{"INSERT INTO a VALUES ('a;b')", "SELECT 1"},
+	},
+	{
+		name:     "Delimiter in double quoted string",
+		content:  `INSERT INTO a VALUES ("a;b"); SELECT 1;`,
+		expected: []string{`INSERT INTO a VALUES ("a;b")`, "SELECT 1"},
+	},
+	{
+		name:     "Escaped quotes in string",
+		content:  `INSERT INTO a VALUES ('it''s;', 'it\'s;'); SELECT 1;`,
+		expected: []string{`INSERT INTO a VALUES ('it''s;', 'it\'s;')`, "SELECT 1"},
+	},
+	{
+		name:     "Delimiter in backticks",
+		content:  "CREATE TABLE `a;b` (`c;d` INT); SELECT 1;",
+		expected: []string{"CREATE TABLE `a;b` (`c;d` INT)", "SELECT 1"},
+	},
+	{
+		name:     "Backslash in backticks",
+		content:  "CREATE TABLE `a\\` (id INT); SELECT 1;",
+		expected: []string{"CREATE TABLE `a\\` (id INT)", "SELECT 1"},
+	},
+	{
+		name:     "Delimiter in dash comment",
+		content:  "SELECT 1; -- a comment; with a semicolon\nSELECT 2;",
+		expected: []string{"SELECT 1", "-- a comment; with a semicolon\nSELECT 2"},
+	},
+	{
+		name:     "Double dash without whitespace",
+		content:  "SELECT 1--1; SELECT 2;",
+		expected: []string{"SELECT 1--1", "SELECT 2"},
+	},
+	{
+		name:     "Delimiter in hash comment",
+		content:  "SELECT 1 # a comment; with a semicolon\n; SELECT 2",
+		expected: []string{"SELECT 1 # a comment; with a semicolon", "SELECT 2"},
+	},
+	{
+		name:     "Delimiter in block comment",
+		content:  "SELECT /* a; b */ 1; SELECT 2;",
+		expected: []string{"SELECT /* a; b */ 1", "SELECT 2"},
+	},
+	{
+		name:     "Trailing comment is omitted",
+		content:  "SELECT 1;\n-- the end\n/* really */",
+		expected: []string{"SELECT 1"},
+	},
+	{
+		name:     "Executable comment",
+		content:  "/*!40101 SET NAMES utf8 */;\nSELECT 1;",
+		expected: []string{"/*!40101 SET NAMES utf8 */", "SELECT 1"},
+	},
+	{
+		name: "Delimiter directive",
+		content: "DELIMITER $$\n" +
+			"CREATE PROCEDURE p()\nBEGIN\n  SELECT 1;\n  SELECT 2;\nEND$$\n" +
+			"DELIMITER ;\n" +
+			"CALL p();\n",
+		expected: []string{
+			"CREATE PROCEDURE p()\nBEGIN\n  SELECT 1;\n  SELECT 2;\nEND",
+			"CALL p()",
+		},
+	},
+	{
+		name: "Delimiter directive for trigger, in lower case",
+		content: "-- create the trigger\ndelimiter //\n" +
+			"CREATE TRIGGER t BEFORE INSERT ON a FOR EACH ROW BEGIN SET NEW.b = ';'; END//\n" +
+			"delimiter ;",
+		expected: []string{
+			"CREATE TRIGGER t BEFORE INSERT ON a FOR EACH ROW BEGIN SET NEW.b = ';'; END",
+		},
+	},
+	{
+		name:     "Delimiter directive mid statement is not a directive",
+		content:  "SELECT 1 AS\nDELIMITER ;",
+		expected: []string{"SELECT 1 AS\nDELIMITER"},
+	},
+	{
+		name:     "Unterminated string",
+		content:  "SELECT 1; SELECT 'a;",
+		expected: []string{"SELECT 1", "SELECT 'a;"},
+	},
+	{
+		name:     "Unterminated block comment",
+		content:  "SELECT 1; /* a;",
+		expected: []string{"SELECT 1"},
+	},
+}
+
+func TestSplitStatements(t *testing.T) {
+	for _, test := range splitStatementsTests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, splitStatements(test.content))
+		})
+	}
+}
@@ -0,0 +1,221 @@
+package mysql
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/reecerussell/migrations"
+)
+
+// newMockProvider returns a MySQL provider, using a mock connection pool.
+func newMockProvider(t *testing.T) (*MySQL, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+
+	t.Cleanup(func() {
+		db.Close()
+	})
+
+	return &MySQL{HistoryTableName: defaultHistoryTableName, db: db}, mock
+}
+
+func expectHistoryTable(mock sqlmock.Sqlmock) {
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT COUNT").WithArgs("__migration_history", "checksum").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectQuery("SELECT COUNT").WithArgs("__migration_history", "note").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+}
+
+const (
+	applyHistory    = "INSERT INTO `__migration_history` (`name`,`date_applied`,`checksum`) VALUES (?, UTC_TIMESTAMP(), ?);"
+	rollbackHistory = "DELETE FROM `__migration_history` WHERE `name` = ?;"
+
+	// procedureContent uses the DELIMITER directive, so is split into two statements.
+	procedureContent = "DELIMITER $$\n" +
+		"CREATE PROCEDURE activate_users()\nBEGIN\n  UPDATE users SET active = 1;\nEND$$\n" +
+		"DELIMITER ;\n" +
+		"CALL activate_users();\n"
+	procedureStatement = "CREATE PROCEDURE activate_users()\nBEGIN\n  UPDATE users SET active = 1;\nEND"
+)
+
+func TestTransaction(t *testing.T) {
+	testError := errors.New("an error occurred")
+
+	tests := []struct {
+		name      string
+		rollback  bool
+		withoutTx bool
+		cancelled bool
+		content   string
+		expect    func(mock sqlmock.Sqlmock)
+		phase     migrations.TxPhase
+		err       error
+	}{
+		{
+			name:    "Apply Given Delimiter Runs Each Statement In Transaction",
+			content: procedureContent,
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta(procedureStatement)).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec(regexp.QuoteMeta("CALL activate_users()")).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(regexp.QuoteMeta(applyHistory)).
+					WithArgs("MyMigration", migrations.Checksum(procedureContent)).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name:    "Apply Where Begin Fails",
+			content: "UPDATE users SET active = 1;",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin().WillReturnError(testError)
+			},
+			phase: migrations.TxBegin,
+			err:   testError,
+		},
+		{
+			name:    "Apply Where Second Statement Fails Rolls Back",
+			content: procedureContent,
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta(procedureStatement)).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec(regexp.QuoteMeta("CALL activate_users()")).WillReturnError(testError)
+				mock.ExpectRollback()
+			},
+			phase: migrations.TxExec,
+			err:   testError,
+		},
+		{
+			name:    "Apply Where History Fails Rolls Back",
+			content: "UPDATE users SET active = 1;",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta("UPDATE users SET active = 1")).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(regexp.QuoteMeta(applyHistory)).WillReturnError(testError)
+				mock.ExpectRollback()
+			},
+			phase: migrations.TxHistory,
+			err:   testError,
+		},
+		{
+			name:    "Apply Where Commit Fails",
+			content: "UPDATE users SET active = 1;",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta("UPDATE users SET active = 1")).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(regexp.QuoteMeta(applyHistory)).WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit().WillReturnError(testError)
+			},
+			phase: migrations.TxCommit,
+			err:   testError,
+		},
+		{
+			name:     "Rollback Removes History In Transaction",
+			rollback: true,
+			content:  "UPDATE users SET active = 0;",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta("UPDATE users SET active = 0")).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(regexp.QuoteMeta(rollbackHistory)).WithArgs("MyMigration").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name:     "Rollback Where History Fails Rolls Back",
+			rollback: true,
+			content:  "UPDATE users SET active = 0;",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta("UPDATE users SET active = 0")).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(regexp.QuoteMeta(rollbackHistory)).WillReturnError(testError)
+				mock.ExpectRollback()
+			},
+			phase: migrations.TxHistory,
+			err:   testError,
+		},
+		{
+			name:      "Apply Without Transaction Records History After Statements",
+			withoutTx: true,
+			content:   procedureContent,
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(regexp.QuoteMeta(procedureStatement)).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec(regexp.QuoteMeta("CALL activate_users()")).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(regexp.QuoteMeta(applyHistory)).
+					WithArgs("MyMigration", migrations.Checksum(procedureContent)).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+			},
+		},
+		{
+			name:      "Apply Without Transaction Where Statement Fails Does Not Record History",
+			withoutTx: true,
+			content:   procedureContent,
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(regexp.QuoteMeta(procedureStatement)).WillReturnError(testError)
+			},
+			phase: migrations.TxExec,
+			err:   testError,
+		},
+		{
+			name:      "Rollback Without Transaction Where Connection Fails",
+			rollback:  true,
+			withoutTx: true,
+			cancelled: true,
+			content:   "UPDATE users SET active = 0;",
+			expect:    func(mock sqlmock.Sqlmock) {},
+			phase:     migrations.TxBegin,
+			err:       context.Canceled,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			p, mock := newMockProvider(t)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if test.withoutTx {
+				ctx = migrations.ContextWithoutTransaction(ctx)
+			}
+
+			if !test.rollback {
+				expectHistoryTable(mock)
+			}
+			test.expect(mock)
+
+			if test.cancelled {
+				cancel()
+			}
+
+			var err error
+			if test.rollback {
+				err = p.Rollback(ctx, "MyMigration", test.content)
+			} else {
+				err = p.Apply(ctx, "MyMigration", test.content)
+			}
+
+			if test.phase == "" {
+				assert.NoError(t, err)
+			} else {
+				var txErr *migrations.TxError
+				if assert.True(t, errors.As(err, &txErr)) {
+					assert.Equal(t, "MyMigration", txErr.Migration)
+					assert.Equal(t, test.phase, txErr.Phase)
+				}
+				assert.True(t, errors.Is(err, test.err))
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestTransaction_GivenUnknownIsolationLevel_ReturnsError(t *testing.T) {
+	p, err := New(migrations.ConfigMap{"connectionString": "test", "isolationLevel": "eventual"})
+	assert.Nil(t, p)
+	assert.EqualError(t, err, "unknown isolation level: eventual")
+}
@@ -0,0 +1,58 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/reecerussell/migrations"
+)
+
+// newMockProvider returns a Postgres provider, using a mock connection pool.
+func newMockProvider(t *testing.T) (*Postgres, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+
+	t.Cleanup(func() {
+		db.Close()
+	})
+
+	return &Postgres{HistoryTableName: defaultHistoryTableName, db: db}, mock
+}
+
+func expectTryLock(mock sqlmock.Sqlmock, acquired bool) {
+	mock.ExpectQuery("pg_try_advisory_lock").
+		WithArgs("migrations:public." + defaultHistoryTableName).
+		WillReturnRows(sqlmock.NewRows([]string{"acquired"}).AddRow(acquired))
+}
+
+func TestLock_GivenZeroTimeout_WaitsForLock(t *testing.T) {
+	p, mock := newMockProvider(t)
+
+	expectTryLock(mock, false)
+	expectTryLock(mock, true)
+
+	err := p.Lock(context.Background(), 0)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestLock_WhereTimeoutElapses_ReturnsLockError(t *testing.T) {
+	p, mock := newMockProvider(t)
+
+	expectTryLock(mock, false)
+	mock.ExpectQuery("pg_locks").WillReturnRows(sqlmock.NewRows([]string{"holder"}).AddRow("pid 1 (postgres@local)"))
+
+	err := p.Lock(context.Background(), time.Nanosecond)
+
+	var lockErr *migrations.LockError
+	assert.True(t, errors.As(err, &lockErr))
+	assert.Equal(t, "pid 1 (postgres@local)", lockErr.Holder)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,506 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/reecerussell/migrations"
+	"github.com/reecerussell/migrations/providers"
+)
+
+const (
+	defaultHistoryTableName = "__migration_history"
+	defaultSchema           = "public"
+
+	// lockPollInterval is how often to retry acquiring the migration lock.
+	lockPollInterval = 250 * time.Millisecond
+)
+
+// errMaxOpenConns is returned by New if maxOpenConns is 1, as the migration
+// lock holds a connection from the pool for the whole run, which would leave
+// none to run the migrations on.
+var errMaxOpenConns = errors.New("maxOpenConns must be at least 2, as the migration lock holds a connection")
+
+func init() {
+	providers.Add("postgres", New)
+}
+
+// Postgres is a migration provider for PostgreSQL.
+type Postgres struct {
+	ConnectionString string
+	HistoryTableName string
+	Schema           string
+
+	// MaxOpenConns and MaxIdleConns configure the connection pool, which is
+	// opened on first use and reused until Close is called. Zero values
+	// leave the database/sql defaults in place.
+	MaxOpenConns int
+	MaxIdleConns int
+
+	// db is the connection pool, and lockConn holds the
+	// connection, taken from db, which owns the migration lock.
+	db       *sql.DB
+	lockConn *sql.Conn
+
+	// historyTableReady is set once the history table has been
+	// ensured, so it's only checked once per connection pool.
+	historyTableReady bool
+}
+
+// New returns a new instance of Postgres. Implementing providers.ConstructorFunc,
+// New takes a migrations.ConfigMap, which is used to populate ConnectionString, resolved
+// using migrations.ResolveConnectionString, falling back to CONNECTION_STRING, as given,
+// HistoryTableName and Schema, as well as the connection pool's size. An error is returned
+// if the ConfigMap is invalid, or neither connection string is set.
+func New(conf migrations.ConfigMap) (migrations.Provider, error) {
+	p := &Postgres{}
+
+	connectionString, fromConfig, err := conf.RawConnectionString()
+	if err != nil {
+		return nil, err
+	}
+
+	if connectionString == "" {
+		return nil, migrations.ErrNoConnectionString
+	}
+
+	if fromConfig {
+		connectionString, err = migrations.ResolveConnectionString(connectionString)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	p.ConnectionString = connectionString
+
+	p.HistoryTableName, err = conf.GetString("historyTableName", defaultHistoryTableName)
+	if err != nil {
+		return nil, err
+	}
+
+	p.Schema, err = conf.GetString("schema", defaultSchema)
+	if err != nil {
+		return nil, err
+	}
+
+	p.MaxOpenConns, err = conf.GetInt("maxOpenConns", 0)
+	if err != nil {
+		return nil, err
+	}
+
+	if p.MaxOpenConns == 1 {
+		return nil, errMaxOpenConns
+	}
+
+	p.MaxIdleConns, err = conf.GetInt("maxIdleConns", 0)
+	if err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// GetAppliedMigrations queries the migration history table for all applied migrations.
+func (p *Postgres) GetAppliedMigrations(ctx context.Context) ([]*migrations.Migration, error) {
+	db, err := p.openConn(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	p.ensureHistoryTable(ctx, db)
+
+	query := fmt.Sprintf("SELECT id, name, date_applied, checksum FROM %s ORDER BY id;", p.historyTable())
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var appliedMigrations []*migrations.Migration
+
+	for rows.Next() {
+		var m migrations.Migration
+		var checksum sql.NullString
+
+		err := rows.Scan(
+			&m.ID,
+			&m.Name,
+			&m.DateApplied,
+			&checksum,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		m.Checksum = checksum.String
+		appliedMigrations = append(appliedMigrations, &m)
+	}
+
+	return appliedMigrations, nil
+}
+
+// ensureHistoryTable ensures the schema, and the table with the name
+// historyTableName within it, exists, adding the note column to tables
+// created by previous versions. Should be provided a valid instance of *sql.DB.
+func (p *Postgres) ensureHistoryTable(ctx context.Context, db *sql.DB) {
+	if p.historyTableReady {
+		return
+	}
+
+	query := fmt.Sprintf(
+		`CREATE SCHEMA IF NOT EXISTS %[1]s;
+		CREATE TABLE IF NOT EXISTS %[2]s (
+			id SERIAL PRIMARY KEY,
+			name VARCHAR(255) NOT NULL,
+			date_applied TIMESTAMP NOT NULL,
+			checksum VARCHAR(64) NULL,
+			note TEXT NULL
+		);
+		ALTER TABLE %[2]s ADD COLUMN IF NOT EXISTS note TEXT NULL;`,
+		pq.QuoteIdentifier(p.schema()),
+		p.historyTable(),
+	)
+
+	// This should never return an error, as it's given a valid
+	// *sql.DB instance, with an open connection. Plus the query
+	// is valid. If it does, the table is ensured again next time.
+	if _, err := db.ExecContext(ctx, query); err == nil {
+		p.historyTableReady = true
+	}
+}
+
+// Apply applies the migration, m, to the database, as well as adding a record
+// to the migration history table, including the checksum of content. As PostgreSQL
+// supports transactional DDL, the migration and its history record are applied atomically.
+func (p *Postgres) Apply(ctx context.Context, name, content string) error {
+	db, err := p.openConn(ctx)
+	if err != nil {
+		return err
+	}
+
+	p.ensureHistoryTable(ctx, db)
+
+	query := fmt.Sprintf("INSERT INTO %s (name, date_applied, checksum) VALUES ($1, NOW() AT TIME ZONE 'utc', $2);", p.historyTable())
+
+	return p.run(ctx, db, migrations.Up, name, content, query, name, migrations.Checksum(content))
+}
+
+// Rollback rolls back the migration, m, then removes the record from
+// the migration history table, atomically.
+func (p *Postgres) Rollback(ctx context.Context, name, content string) error {
+	db, err := p.openConn(ctx)
+	if err != nil {
+		return err
+	}
+
+	query := fmt.Sprintf("DELETE FROM %s WHERE name = $1;", p.historyTable())
+
+	return p.run(ctx, db, migrations.Down, name, content, query, name)
+}
+
+// run executes the content of the migration with the given name, then updates the
+// migration history, using the query and its args, in a transaction. If ctx says the
+// migration isn't run in a transaction, such as for CREATE INDEX CONCURRENTLY, both
+// are executed on a single connection, and the history is only updated once the
+// content has succeeded.
+func (p *Postgres) run(ctx context.Context, db *sql.DB, d migrations.Direction, name, content, query string, args ...interface{}) error {
+	if !migrations.TransactionFromContext(ctx) {
+		conn, err := p.searchPathConn(ctx, db)
+		if err != nil {
+			return &migrations.TxError{Migration: name, Phase: migrations.TxBegin, Err: err}
+		}
+		defer conn.Close()
+
+		err = execContent(ctx, conn, d, name, content)
+		if err != nil {
+			return &migrations.TxError{Migration: name, Phase: migrations.TxExec, Err: err}
+		}
+
+		_, err = conn.ExecContext(ctx, query, args...)
+		if err != nil {
+			return &migrations.TxError{Migration: name, Phase: migrations.TxHistory, Err: err}
+		}
+
+		return nil
+	}
+
+	tx, err := p.beginTx(ctx, db)
+	if err != nil {
+		return &migrations.TxError{Migration: name, Phase: migrations.TxBegin, Err: err}
+	}
+
+	err = execContent(ctx, tx, d, name, content)
+	if err != nil {
+		tx.Rollback()
+		return &migrations.TxError{Migration: name, Phase: migrations.TxExec, Err: err}
+	}
+
+	_, err = tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		tx.Rollback()
+		return &migrations.TxError{Migration: name, Phase: migrations.TxHistory, Err: err}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return &migrations.TxError{Migration: name, Phase: migrations.TxCommit, Err: err}
+	}
+
+	return nil
+}
+
+// execer is implemented by *sql.Tx and *sql.Conn, which migrations are executed on.
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// execContent executes the content of the migration with the given name, as
+// a whole, reporting it to the Observer held by ctx as a single statement.
+func execContent(ctx context.Context, e execer, d migrations.Direction, name, content string) error {
+	start := time.Now()
+	_, err := e.ExecContext(ctx, content)
+	migrations.StatementExecuted(ctx, d, name, content, start, err)
+
+	return err
+}
+
+// searchPathConn returns a connection, in which unqualified names resolve
+// to the configured schema, until it's closed, when the search_path is reset.
+func (p *Postgres) searchPathConn(ctx context.Context, db *sql.DB) (*searchPathConn, error) {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	query := fmt.Sprintf("SET search_path TO %s;", pq.QuoteIdentifier(p.schema()))
+	_, err = conn.ExecContext(ctx, query)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return &searchPathConn{conn}, nil
+}
+
+// searchPathConn is a connection, returned by Postgres.searchPathConn, which
+// resets the search_path before returning the connection to the pool.
+type searchPathConn struct {
+	*sql.Conn
+}
+
+func (c *searchPathConn) Close() error {
+	c.Conn.ExecContext(context.Background(), "RESET search_path;")
+
+	return c.Conn.Close()
+}
+
+// beginTx starts a transaction, in which unqualified names
+// resolve to the configured schema.
+func (p *Postgres) beginTx(ctx context.Context, db *sql.DB) (*sql.Tx, error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	query := fmt.Sprintf("SET LOCAL search_path TO %s;", pq.QuoteIdentifier(p.schema()))
+	_, err = tx.ExecContext(ctx, query)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	return tx, nil
+}
+
+// MarkApplied adds a record of the migration to the migration
+// history table, including the checksum of content and the note, if any,
+// without applying it.
+func (p *Postgres) MarkApplied(ctx context.Context, name, content, note string) error {
+	db, err := p.openConn(ctx)
+	if err != nil {
+		return err
+	}
+
+	p.ensureHistoryTable(ctx, db)
+
+	query := fmt.Sprintf("INSERT INTO %s (name, date_applied, checksum, note) VALUES ($1, NOW() AT TIME ZONE 'utc', $2, $3);", p.historyTable())
+	_, err = db.ExecContext(ctx, query, name, migrations.Checksum(content), sql.NullString{String: note, Valid: note != ""})
+
+	return err
+}
+
+// MarkUnapplied removes the record of the migration from
+// the migration history table, without rolling it back.
+func (p *Postgres) MarkUnapplied(ctx context.Context, name string) error {
+	db, err := p.openConn(ctx)
+	if err != nil {
+		return err
+	}
+
+	query := fmt.Sprintf("DELETE FROM %s WHERE name = $1;", p.historyTable())
+	_, err = db.ExecContext(ctx, query, name)
+
+	return err
+}
+
+// Lock acquires an exclusive session level advisory lock, which is owned by a
+// dedicated connection, taken from the pool, held until Unlock is called. As pg_advisory_lock
+// can't time out, pg_try_advisory_lock is polled until the timeout elapses.
+func (p *Postgres) Lock(ctx context.Context, timeout time.Duration) error {
+	db, err := p.openConn(ctx)
+	if err != nil {
+		return err
+	}
+
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Without a timeout, the lock is polled for until ctx is done.
+	deadline := time.Now().Add(timeout)
+
+	for err == nil {
+		var acquired bool
+		err = conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock(hashtext($1));", p.lockKey()).Scan(&acquired)
+		if err != nil {
+			break
+		}
+
+		if acquired {
+			p.lockConn = conn
+			return nil
+		}
+
+		if timeout > 0 && !time.Now().Before(deadline) {
+			err = &migrations.LockError{Timeout: timeout, Holder: p.lockHolder(ctx, conn)}
+			break
+		}
+
+		select {
+		case <-time.After(lockPollInterval):
+		case <-ctx.Done():
+			err = ctx.Err()
+		}
+	}
+
+	conn.Close()
+
+	return err
+}
+
+// lockHolder returns a description of the backend holding the migration lock,
+// or an empty string if it can't be determined, i.e. if it has since been released.
+func (p *Postgres) lockHolder(ctx context.Context, conn *sql.Conn) string {
+	var holder string
+	conn.QueryRowContext(ctx,
+		`SELECT CONCAT('pid ', a.pid, ' (', a.usename, '@', COALESCE(HOST(a.client_addr), 'local'), ')')
+		FROM pg_locks l
+		INNER JOIN pg_stat_activity a ON a.pid = l.pid
+		WHERE l.locktype = 'advisory' AND l.granted AND l.objsubid = 1
+			AND ((l.classid::BIGINT << 32) | l.objid::BIGINT) = hashtext($1)::BIGINT
+		LIMIT 1;`,
+		p.lockKey(),
+	).Scan(&holder)
+
+	return holder
+}
+
+// Unlock releases the advisory lock acquired by Lock, returning its connection to the pool.
+func (p *Postgres) Unlock(ctx context.Context) error {
+	if p.lockConn == nil {
+		return nil
+	}
+
+	defer func() {
+		p.lockConn.Close()
+		p.lockConn = nil
+	}()
+
+	_, err := p.lockConn.ExecContext(ctx, "SELECT pg_advisory_unlock(hashtext($1));", p.lockKey())
+
+	return err
+}
+
+// lockKey returns the key hashed to identify the advisory lock,
+// which is specific to the history table.
+func (p *Postgres) lockKey() string {
+	return "migrations:" + p.schema() + "." + p.HistoryTableName
+}
+
+// historyTable returns the quoted, schema qualified, name of the history table.
+func (p *Postgres) historyTable() string {
+	return pq.QuoteIdentifier(p.schema()) + "." + pq.QuoteIdentifier(p.HistoryTableName)
+}
+
+// schema returns the configured schema, or the default if there is none.
+func (p *Postgres) schema() string {
+	if p.Schema == "" {
+		return defaultSchema
+	}
+
+	return p.Schema
+}
+
+// Template returns the initial content of a migration file, created by the create command.
+func (p *Postgres) Template(name string, d migrations.Direction) string {
+	return fmt.Sprintf("-- %s (%s)\n"+
+		"-- The content of this file is executed in a transaction, with the search_path\n"+
+		"-- set to the configured schema, so unqualified names resolve to it.\n\n", name, d)
+}
+
+// openConn returns the connection pool, opening it on first use.
+func (p *Postgres) openConn(ctx context.Context) (*sql.DB, error) {
+	if p.db != nil {
+		return p.db, nil
+	}
+
+	if p.ConnectionString == "" {
+		return nil, migrations.ErrNoConnectionString
+	}
+
+	db, err := sql.Open("postgres", p.ConnectionString)
+	if err != nil {
+		return nil, err
+	}
+
+	db.SetMaxOpenConns(p.MaxOpenConns)
+	if p.MaxIdleConns > 0 {
+		db.SetMaxIdleConns(p.MaxIdleConns)
+	}
+
+	err = db.PingContext(ctx)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	p.db = db
+
+	return db, nil
+}
+
+// Close releases the migration lock's connection, if held,
+// and closes the connection pool. The provider can be reused,
+// as the pool is reopened when next needed.
+func (p *Postgres) Close() error {
+	if p.lockConn != nil {
+		p.lockConn.Close()
+		p.lockConn = nil
+	}
+
+	if p.db == nil {
+		return nil
+	}
+
+	err := p.db.Close()
+	p.db = nil
+	p.historyTableReady = false
+
+	return err
+}
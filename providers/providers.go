@@ -2,6 +2,8 @@ package providers
 
 import (
 	"fmt"
+	"sort"
+	"strings"
 	"sync"
 
 	"github.com/reecerussell/migrations"
@@ -13,8 +15,21 @@ var (
 )
 
 // ConstructorFunc is used as a build func to build a provider,
-// giving it access to a ConfigMap, if needed.
-type ConstructorFunc func(migrations.ConfigMap) migrations.Provider
+// giving it access to a ConfigMap, if needed. An error should
+// be returned if the ConfigMap is invalid.
+type ConstructorFunc func(migrations.ConfigMap) (migrations.Provider, error)
+
+// NotRegisteredError is returned by Get if no provider is registered with the name.
+type NotRegisteredError struct {
+	Name string
+
+	// Registered holds the names of the registered providers, in alphabetical order.
+	Registered []string
+}
+
+func (e *NotRegisteredError) Error() string {
+	return fmt.Sprintf("no provider '%s' is registered, expected one of: %s", e.Name, strings.Join(e.Registered, ", "))
+}
 
 // Add registers a provider by loading a constructur and name
 // into memory, which can then be consumer via the Get function.
@@ -27,15 +42,31 @@ func Add(name string, p ConstructorFunc) {
 
 // Get retrieves a provider with the given name, by building it
 // using the registered ConstructorFunc, providing it with
-// the given ConfigMap.
-func Get(name string, conf migrations.ConfigMap) migrations.Provider {
+// the given ConfigMap. If no provider is registered with the
+// name, a *NotRegisteredError is returned.
+func Get(name string, conf migrations.ConfigMap) (migrations.Provider, error) {
 	mu.Lock()
-	defer mu.Unlock()
-
 	p, ok := prvs[name]
+	mu.Unlock()
+
 	if !ok {
-		panic(fmt.Sprintf("no provider '%s' is registered", name))
+		return nil, &NotRegisteredError{Name: name, Registered: List()}
 	}
 
 	return p(conf)
 }
+
+// List returns the names of the registered providers, in alphabetical order.
+func List() []string {
+	mu.Lock()
+	defer mu.Unlock()
+
+	names := make([]string, 0, len(prvs))
+	for name := range prvs {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
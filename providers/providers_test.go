@@ -1,6 +1,8 @@
 package providers
 
 import (
+	"errors"
+	"sort"
 	"testing"
 
 	"github.com/golang/mock/gomock"
@@ -16,13 +18,16 @@ func TestAdd_GivenNewProvider_AddsSuccessfully(t *testing.T) {
 
 	mockProvider := mock.NewMockProvider(ctrl)
 
-	Add("TestAdd_GivenNewProvider_AddsSuccessfully", func(conf migrations.ConfigMap) migrations.Provider {
-		return mockProvider
+	Add("TestAdd_GivenNewProvider_AddsSuccessfully", func(conf migrations.ConfigMap) (migrations.Provider, error) {
+		return mockProvider, nil
 	})
 
 	p, ok := prvs["TestAdd_GivenNewProvider_AddsSuccessfully"]
-	assert.Equal(t, mockProvider, p(nil))
 	assert.True(t, ok)
+
+	provider, err := p(nil)
+	assert.Equal(t, mockProvider, provider)
+	assert.NoError(t, err)
 }
 
 func TestGet_GivenValidName_ReturnsProvider(t *testing.T) {
@@ -30,28 +35,50 @@ func TestGet_GivenValidName_ReturnsProvider(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockProvider := mock.NewMockProvider(ctrl)
-	prvs["TestGet_GivenValidName_ReturnsProvider"] = func(conf migrations.ConfigMap) migrations.Provider {
+	prvs["TestGet_GivenValidName_ReturnsProvider"] = func(conf migrations.ConfigMap) (migrations.Provider, error) {
 		assert.Equal(t, "test", conf["value"])
 
-		return mockProvider
+		return mockProvider, nil
 	}
 
 	testConfig := migrations.ConfigMap{
 		"value": "test",
 	}
 
-	p := Get("TestGet_GivenValidName_ReturnsProvider", testConfig)
+	p, err := Get("TestGet_GivenValidName_ReturnsProvider", testConfig)
 	assert.Equal(t, mockProvider, p)
+	assert.NoError(t, err)
 }
 
-func TestGet_GivenInvalidName_Panics(t *testing.T) {
-	ctrl := gomock.NewController(t)
-	defer ctrl.Finish()
+func TestGet_GivenInvalidName_ReturnsNotRegisteredError(t *testing.T) {
+	Add("TestGet_GivenInvalidName_Registered", nil)
+
+	p, err := Get("TestGet_GivenInvalidName_ReturnsNotRegisteredError", nil)
+	assert.Nil(t, p)
+
+	var notRegistered *NotRegisteredError
+	assert.True(t, errors.As(err, &notRegistered))
+	assert.Equal(t, "TestGet_GivenInvalidName_ReturnsNotRegisteredError", notRegistered.Name)
+	assert.Contains(t, notRegistered.Registered, "TestGet_GivenInvalidName_Registered")
+	assert.Contains(t, err.Error(), "no provider 'TestGet_GivenInvalidName_ReturnsNotRegisteredError' is registered, expected one of: ")
+}
+
+func TestGet_WhereConstructorFails_ReturnsError(t *testing.T) {
+	testError := errors.New("an error occurred")
+	Add("TestGet_WhereConstructorFails_ReturnsError", func(conf migrations.ConfigMap) (migrations.Provider, error) {
+		return nil, testError
+	})
+
+	p, err := Get("TestGet_WhereConstructorFails_ReturnsError", nil)
+	assert.Nil(t, p)
+	assert.Equal(t, testError, err)
+}
 
-	defer func() {
-		r := recover()
-		assert.Equal(t, "no provider 'TestGet_GivenInvalidName_Panics' is registered", r)
-	}()
+func TestList_ReturnsRegisteredProvidersInOrder(t *testing.T) {
+	Add("TestList_B", nil)
+	Add("TestList_A", nil)
 
-	_ = Get("TestGet_GivenInvalidName_Panics", nil)
+	names := List()
+	assert.Subset(t, names, []string{"TestList_A", "TestList_B"})
+	assert.True(t, sort.StringsAreSorted(names))
 }
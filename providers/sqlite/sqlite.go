@@ -0,0 +1,328 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/reecerussell/migrations"
+	"github.com/reecerussell/migrations/providers"
+
+	// SQLite driver, written in pure Go, so doesn't require cgo.
+	_ "modernc.org/sqlite"
+)
+
+const defaultHistoryTableName = "__migration_history"
+
+func init() {
+	providers.Add("sqlite", New)
+}
+
+// SQLite is a migration provider for SQLite.
+type SQLite struct {
+	ConnectionString string
+	HistoryTableName string
+
+	// MaxOpenConns and MaxIdleConns configure the connection pool, which is
+	// opened on first use and reused until Close is called. Zero values
+	// leave the database/sql defaults in place.
+	MaxOpenConns int
+	MaxIdleConns int
+
+	db *sql.DB
+
+	// historyTableReady is set once the history table has been
+	// ensured, so it's only checked once per connection pool.
+	historyTableReady bool
+}
+
+// New returns a new instance of SQLite. Implementing providers.ConstructorFunc,
+// New takes a migrations.ConfigMap, which is used to populate HistoryTableName,
+// and ConnectionString, using the database path, falling back to CONNECTION_STRING.
+// An error is returned if the ConfigMap is invalid.
+func New(conf migrations.ConfigMap) (migrations.Provider, error) {
+	p := &SQLite{}
+
+	var err error
+	p.HistoryTableName, err = conf.GetString("historyTableName", defaultHistoryTableName)
+	if err != nil {
+		return nil, err
+	}
+
+	p.ConnectionString, err = conf.GetString("path", os.Getenv("CONNECTION_STRING"))
+	if err != nil {
+		return nil, err
+	}
+
+	p.MaxOpenConns, err = conf.GetInt("maxOpenConns", 0)
+	if err != nil {
+		return nil, err
+	}
+
+	p.MaxIdleConns, err = conf.GetInt("maxIdleConns", 0)
+	if err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// GetAppliedMigrations queries the migration history table for all applied migrations.
+func (p *SQLite) GetAppliedMigrations(ctx context.Context) ([]*migrations.Migration, error) {
+	db, err := p.openConn(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	p.ensureHistoryTable(ctx, db)
+
+	query := fmt.Sprintf(`SELECT id, name, date_applied, checksum FROM "%s" ORDER BY id;`, p.HistoryTableName)
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var appliedMigrations []*migrations.Migration
+
+	for rows.Next() {
+		var m migrations.Migration
+		var checksum sql.NullString
+
+		err := rows.Scan(
+			&m.ID,
+			&m.Name,
+			&m.DateApplied,
+			&checksum,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		m.Checksum = checksum.String
+		appliedMigrations = append(appliedMigrations, &m)
+	}
+
+	return appliedMigrations, nil
+}
+
+// ensureHistoryTable ensures the table with the name historyTableName exists,
+// adding the note column to tables created by previous versions.
+// Should be provided a valid instance of *sql.DB.
+func (p *SQLite) ensureHistoryTable(ctx context.Context, db *sql.DB) {
+	if p.historyTableReady {
+		return
+	}
+
+	query := fmt.Sprintf(
+		`CREATE TABLE IF NOT EXISTS "%s" (
+			id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+			name VARCHAR(255) NOT NULL,
+			date_applied DATETIME NOT NULL,
+			checksum VARCHAR(64) NULL,
+			note TEXT NULL
+		);`,
+		p.HistoryTableName,
+	)
+
+	// This should never return an error, as it's given a valid
+	// *sql.DB instance, with an open connection. Plus the query
+	// is valid. If it does, the table is ensured again next time.
+	if _, err := db.ExecContext(ctx, query); err != nil {
+		return
+	}
+
+	var count int
+	row := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM pragma_table_info(?) WHERE name = 'note';", p.HistoryTableName)
+	if err := row.Scan(&count); err != nil {
+		return
+	}
+
+	if count == 0 {
+		query = fmt.Sprintf(`ALTER TABLE "%s" ADD COLUMN note TEXT NULL;`, p.HistoryTableName)
+		if _, err := db.ExecContext(ctx, query); err != nil {
+			return
+		}
+	}
+
+	p.historyTableReady = true
+}
+
+// Apply applies the migration, m, to the database, as well as adding a record
+// to the migration history table, including the checksum of content. As SQLite
+// supports transactional DDL, the migration and its history record are applied atomically.
+func (p *SQLite) Apply(ctx context.Context, name, content string) error {
+	db, err := p.openConn(ctx)
+	if err != nil {
+		return err
+	}
+
+	p.ensureHistoryTable(ctx, db)
+
+	query := fmt.Sprintf(`INSERT INTO "%s" (name, date_applied, checksum) VALUES (?, ?, ?);`, p.HistoryTableName)
+
+	return p.run(ctx, db, migrations.Up, name, content, query, name, time.Now().UTC(), migrations.Checksum(content))
+}
+
+// Rollback rolls back the migration, m, then removes the record from
+// the migration history table, atomically.
+func (p *SQLite) Rollback(ctx context.Context, name, content string) error {
+	db, err := p.openConn(ctx)
+	if err != nil {
+		return err
+	}
+
+	query := fmt.Sprintf(`DELETE FROM "%s" WHERE name = ?;`, p.HistoryTableName)
+
+	return p.run(ctx, db, migrations.Down, name, content, query, name)
+}
+
+// run executes the content of the migration with the given name, then updates the
+// migration history, using the query and its args, in a transaction. If ctx says the
+// migration isn't run in a transaction, such as for VACUUM, both are executed on a
+// single connection, and the history is only updated once the content has succeeded.
+func (p *SQLite) run(ctx context.Context, db *sql.DB, d migrations.Direction, name, content, query string, args ...interface{}) error {
+	if !migrations.TransactionFromContext(ctx) {
+		conn, err := db.Conn(ctx)
+		if err != nil {
+			return &migrations.TxError{Migration: name, Phase: migrations.TxBegin, Err: err}
+		}
+		defer conn.Close()
+
+		err = execContent(ctx, conn, d, name, content)
+		if err != nil {
+			return &migrations.TxError{Migration: name, Phase: migrations.TxExec, Err: err}
+		}
+
+		_, err = conn.ExecContext(ctx, query, args...)
+		if err != nil {
+			return &migrations.TxError{Migration: name, Phase: migrations.TxHistory, Err: err}
+		}
+
+		return nil
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return &migrations.TxError{Migration: name, Phase: migrations.TxBegin, Err: err}
+	}
+
+	err = execContent(ctx, tx, d, name, content)
+	if err != nil {
+		tx.Rollback()
+		return &migrations.TxError{Migration: name, Phase: migrations.TxExec, Err: err}
+	}
+
+	_, err = tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		tx.Rollback()
+		return &migrations.TxError{Migration: name, Phase: migrations.TxHistory, Err: err}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return &migrations.TxError{Migration: name, Phase: migrations.TxCommit, Err: err}
+	}
+
+	return nil
+}
+
+// execer is implemented by *sql.Tx and *sql.Conn, which migrations are executed on.
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// execContent executes the content of the migration with the given name, as
+// a whole, reporting it to the Observer held by ctx as a single statement.
+func execContent(ctx context.Context, e execer, d migrations.Direction, name, content string) error {
+	start := time.Now()
+	_, err := e.ExecContext(ctx, content)
+	migrations.StatementExecuted(ctx, d, name, content, start, err)
+
+	return err
+}
+
+// MarkApplied adds a record of the migration to the migration
+// history table, including the checksum of content and the note, if any,
+// without applying it.
+func (p *SQLite) MarkApplied(ctx context.Context, name, content, note string) error {
+	db, err := p.openConn(ctx)
+	if err != nil {
+		return err
+	}
+
+	p.ensureHistoryTable(ctx, db)
+
+	query := fmt.Sprintf(`INSERT INTO "%s" (name, date_applied, checksum, note) VALUES (?, ?, ?, ?);`, p.HistoryTableName)
+	_, err = db.ExecContext(ctx, query, name, time.Now().UTC(), migrations.Checksum(content), sql.NullString{String: note, Valid: note != ""})
+
+	return err
+}
+
+// MarkUnapplied removes the record of the migration from
+// the migration history table, without rolling it back.
+func (p *SQLite) MarkUnapplied(ctx context.Context, name string) error {
+	db, err := p.openConn(ctx)
+	if err != nil {
+		return err
+	}
+
+	query := fmt.Sprintf(`DELETE FROM "%s" WHERE name = ?;`, p.HistoryTableName)
+	_, err = db.ExecContext(ctx, query, name)
+
+	return err
+}
+
+// Template returns the initial content of a migration file, created by the create command.
+func (p *SQLite) Template(name string, d migrations.Direction) string {
+	return fmt.Sprintf("-- %s (%s)\n"+
+		"-- The content of this file is executed in a transaction.\n\n", name, d)
+}
+
+// openConn returns the connection pool, opening it on first use.
+func (p *SQLite) openConn(ctx context.Context) (*sql.DB, error) {
+	if p.db != nil {
+		return p.db, nil
+	}
+
+	if p.ConnectionString == "" {
+		return nil, errors.New("no database path was provided")
+	}
+
+	db, err := sql.Open("sqlite", p.ConnectionString)
+	if err != nil {
+		return nil, err
+	}
+
+	db.SetMaxOpenConns(p.MaxOpenConns)
+	if p.MaxIdleConns > 0 {
+		db.SetMaxIdleConns(p.MaxIdleConns)
+	}
+
+	err = db.PingContext(ctx)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	p.db = db
+
+	return db, nil
+}
+
+// Close closes the connection pool. The provider can be
+// reused, as the pool is reopened when next needed.
+func (p *SQLite) Close() error {
+	if p.db == nil {
+		return nil
+	}
+
+	err := p.db.Close()
+	p.db = nil
+	p.historyTableReady = false
+
+	return err
+}
@@ -0,0 +1,525 @@
+package sqlite_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/reecerussell/migrations"
+	"github.com/reecerussell/migrations/providers/sqlite"
+)
+
+// testDatabase returns the path to a new database file, which
+// is removed once the test has finished.
+func testDatabase(t *testing.T) string {
+	return filepath.Join(t.TempDir(), "test.db")
+}
+
+func openDB(path string) *sql.DB {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		panic(err)
+	}
+
+	return db
+}
+
+func execute(db *sql.DB, queryf string, inlineArgs ...interface{}) {
+	query := fmt.Sprintf(queryf, inlineArgs...)
+	_, err := db.Exec(query)
+	if err != nil {
+		panic(err)
+	}
+}
+
+func TestNew(t *testing.T) {
+	cnf := migrations.ConfigMap{
+		"historyTableName": "MyMigrationsTable",
+		"path":             "my.db",
+		"maxOpenConns":     5,
+		"maxIdleConns":     2,
+	}
+	provider, err := sqlite.New(cnf)
+	assert.NoError(t, err)
+
+	p := provider.(*sqlite.SQLite)
+
+	assert.Equal(t, "MyMigrationsTable", p.HistoryTableName)
+	assert.Equal(t, "my.db", p.ConnectionString)
+	assert.Equal(t, 5, p.MaxOpenConns)
+	assert.Equal(t, 2, p.MaxIdleConns)
+}
+
+func TestNew_WithoutPath_UsesConnectionString(t *testing.T) {
+	os.Setenv("CONNECTION_STRING", "env.db")
+	t.Cleanup(func() {
+		os.Unsetenv("CONNECTION_STRING")
+	})
+
+	provider, err := sqlite.New(nil)
+	assert.NoError(t, err)
+
+	p := provider.(*sqlite.SQLite)
+
+	assert.Equal(t, "__migration_history", p.HistoryTableName)
+	assert.Equal(t, "env.db", p.ConnectionString)
+}
+
+func TestNew_GivenInvalidConfig_ReturnsError(t *testing.T) {
+	p, err := sqlite.New(migrations.ConfigMap{"maxOpenConns": "five"})
+	assert.Nil(t, p)
+	assert.EqualError(t, err, `config property maxOpenConns must be a whole number, but is "five"`)
+}
+
+func TestGetAppliedMigrations_HavingOneAppliedMigration_ReturnsMigrationSuccessfully(t *testing.T) {
+	path := testDatabase(t)
+	db := openDB(path)
+	defer db.Close()
+
+	execute(db, `CREATE TABLE __migration_history (
+			id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+			name VARCHAR(255) NOT NULL,
+			date_applied DATETIME NOT NULL,
+			checksum VARCHAR(64) NULL
+		);`)
+
+	execute(db, `INSERT INTO __migration_history (name, date_applied, checksum)
+		VALUES ('Test', '2021-01-02 03:04:05', 'abc')`)
+
+	p := &sqlite.SQLite{
+		ConnectionString: path,
+		HistoryTableName: "__migration_history",
+	}
+	appliedMigrations, err := p.GetAppliedMigrations(context.TODO())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(appliedMigrations))
+	assert.Equal(t, "Test", appliedMigrations[0].Name)
+	assert.Equal(t, "abc", appliedMigrations[0].Checksum)
+	assert.Equal(t, 2021, appliedMigrations[0].DateApplied.Year())
+}
+
+func TestGetAppliedMigrations_GivenNoPath_ReturnsError(t *testing.T) {
+	p := &sqlite.SQLite{}
+	appliedMigrations, err := p.GetAppliedMigrations(context.TODO())
+	assert.Nil(t, appliedMigrations)
+	assert.NotNil(t, err)
+}
+
+func TestGetAppliedMigrations_WithInvalidHistoryTableStructure_ReturnsError(t *testing.T) {
+	path := testDatabase(t)
+	db := openDB(path)
+	defer db.Close()
+
+	// create migration history table to simulate a table with the same name,
+	// but with a different table structure, i.e. without the "name" column.
+	execute(db, `CREATE TABLE __migration_history (
+			id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+			date_applied DATETIME NOT NULL
+		);`)
+
+	p := &sqlite.SQLite{
+		ConnectionString: path,
+		HistoryTableName: "__migration_history",
+	}
+	appliedMigrations, err := p.GetAppliedMigrations(context.TODO())
+	assert.Nil(t, appliedMigrations)
+	assert.NotNil(t, err)
+}
+
+func TestApply(t *testing.T) {
+	path := testDatabase(t)
+
+	p := &sqlite.SQLite{
+		ConnectionString: path,
+		HistoryTableName: "__migration_history",
+	}
+	err := p.Apply(context.TODO(), "CreateTable", `CREATE TABLE test_apply (
+			name VARCHAR(255) NOT NULL
+		);
+		INSERT INTO test_apply (name) VALUES ('Test');`)
+
+	db := openDB(path)
+	defer db.Close()
+
+	t.Run("Returns No Error", func(t *testing.T) {
+		assert.NoError(t, err)
+	})
+
+	t.Run("Table Is Created", func(t *testing.T) {
+		row := db.QueryRow("SELECT name FROM test_apply")
+		var name string
+		err = row.Scan(&name)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Test", name)
+	})
+
+	t.Run("Migration History Record Is Inserted", func(t *testing.T) {
+		row := db.QueryRow("SELECT name, checksum FROM __migration_history WHERE name = 'CreateTable'")
+		var name, checksum string
+		err = row.Scan(&name, &checksum)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "CreateTable", name)
+		assert.NotEmpty(t, checksum)
+	})
+}
+
+func TestApply_GivenInMemoryDatabaseWithOneConnection_PersistsBetweenOperations(t *testing.T) {
+	p := &sqlite.SQLite{
+		ConnectionString: ":memory:",
+		HistoryTableName: "__migration_history",
+		MaxOpenConns:     1,
+	}
+	defer p.Close()
+
+	err := p.Apply(context.TODO(), "CreateTable", "CREATE TABLE test_memory (name VARCHAR(255) NOT NULL);")
+	assert.NoError(t, err)
+
+	applied, err := p.GetAppliedMigrations(context.TODO())
+	assert.NoError(t, err)
+	assert.Len(t, applied, 1)
+	assert.Equal(t, "CreateTable", applied[0].Name)
+}
+
+// recordingObserver is an Observer which records the events it receives.
+type recordingObserver struct {
+	events []*migrations.Event
+}
+
+func (o *recordingObserver) Observe(e *migrations.Event) {
+	o.events = append(o.events, e)
+}
+
+func TestApply_WithObserver_ReportsContentAsStatement(t *testing.T) {
+	p := &sqlite.SQLite{
+		ConnectionString: testDatabase(t),
+		HistoryTableName: "__migration_history",
+	}
+	defer p.Close()
+
+	obs := &recordingObserver{}
+	ctx := migrations.ContextWithObserver(context.Background(), obs)
+	err := p.Apply(ctx, "CreateTable", "CREATE TABLE test_observer (name VARCHAR(255) NOT NULL);")
+	assert.NoError(t, err)
+
+	assert.Len(t, obs.events, 1)
+	assert.Equal(t, migrations.EventStatementExecuted, obs.events[0].Type)
+	assert.Equal(t, migrations.Up, obs.events[0].Direction)
+	assert.Equal(t, "CreateTable", obs.events[0].Migration)
+	assert.Equal(t, "CREATE TABLE test_observer (name VARCHAR(255) NOT NULL);", obs.events[0].Statement)
+}
+
+func TestApply_GivenMigrationWithInvalidSQL_ReturnsError(t *testing.T) {
+	p := &sqlite.SQLite{
+		ConnectionString: testDatabase(t),
+		HistoryTableName: "__migration_history",
+	}
+	err := p.Apply(context.TODO(), "TestApply", `CREATE TABLE test_apply (
+		name VARCHAR(255) NO`)
+	assert.NotNil(t, err)
+}
+
+func TestApply_WithoutTransaction_AppliesStatementsOutsideOfTransaction(t *testing.T) {
+	path := testDatabase(t)
+
+	p := &sqlite.SQLite{
+		ConnectionString: path,
+		HistoryTableName: "__migration_history",
+	}
+	defer p.Close()
+
+	// VACUUM fails if run in a transaction.
+	err := p.Apply(migrations.ContextWithoutTransaction(context.TODO()), "Vacuum", "VACUUM;")
+	assert.NoError(t, err)
+
+	db := openDB(path)
+	defer db.Close()
+
+	var name string
+	err = db.QueryRow("SELECT name FROM __migration_history WHERE name = 'Vacuum'").Scan(&name)
+	assert.NoError(t, err)
+}
+
+func TestApply_WithoutTransactionGivenInvalidSQL_DoesNotRecordMigration(t *testing.T) {
+	path := testDatabase(t)
+
+	p := &sqlite.SQLite{
+		ConnectionString: path,
+		HistoryTableName: "__migration_history",
+	}
+	defer p.Close()
+
+	err := p.Apply(migrations.ContextWithoutTransaction(context.TODO()), "Invalid", "CREATE TABLE")
+
+	var txErr *migrations.TxError
+	assert.True(t, errors.As(err, &txErr))
+	assert.Equal(t, migrations.TxExec, txErr.Phase)
+
+	db := openDB(path)
+	defer db.Close()
+
+	err = db.QueryRow("SELECT name FROM __migration_history WHERE name = 'Invalid'").Scan(new(string))
+	assert.Equal(t, sql.ErrNoRows, err)
+}
+
+func TestApply_WithInvalidHistoryTableStructure_RollsBackMigration(t *testing.T) {
+	path := testDatabase(t)
+	db := openDB(path)
+	defer db.Close()
+
+	// Similarly to the other tests, this simulates a table already existing
+	// in the database with the same name, with a different structure.
+	// This misses the "name" column, which will fail to insertion.
+	execute(db, `CREATE TABLE __migration_history (
+			id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+			date_applied DATETIME NOT NULL
+		);`)
+
+	p := &sqlite.SQLite{
+		ConnectionString: path,
+		HistoryTableName: "__migration_history",
+	}
+	err := p.Apply(context.TODO(), "TestApply", `CREATE TABLE test_apply (
+			name VARCHAR(255) NOT NULL
+		)`)
+	assert.NotNil(t, err)
+
+	// As the history record failed to insert, the migration should have been rolled back.
+	row := db.QueryRow("SELECT name FROM sqlite_master WHERE type = 'table' AND name = 'test_apply'")
+	var name string
+	err = row.Scan(&name)
+
+	assert.Equal(t, sql.ErrNoRows, err)
+}
+
+func TestRollback_GivenAppliedMigration_RollsBackSuucessful(t *testing.T) {
+	path := testDatabase(t)
+	db := openDB(path)
+	defer db.Close()
+
+	execute(db, `CREATE TABLE __migration_history (
+			id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+			name VARCHAR(255) NOT NULL,
+			date_applied DATETIME NOT NULL,
+			checksum VARCHAR(64) NULL
+		);`)
+
+	execute(db, "INSERT INTO __migration_history (name, date_applied) VALUES ('CreateTable', CURRENT_TIMESTAMP)")
+
+	execute(db, `CREATE TABLE test_rollback (
+			name VARCHAR(255) NOT NULL
+		)`)
+
+	p := &sqlite.SQLite{
+		ConnectionString: path,
+		HistoryTableName: "__migration_history",
+	}
+	err := p.Rollback(context.TODO(), "CreateTable", `DROP TABLE test_rollback`)
+
+	t.Run("Returns No Error", func(t *testing.T) {
+		assert.NoError(t, err)
+	})
+
+	t.Run("Table Is Dropped", func(t *testing.T) {
+		row := db.QueryRow("SELECT name FROM sqlite_master WHERE type = 'table' AND name = 'test_rollback'")
+		var name string
+		err = row.Scan(&name)
+
+		assert.Equal(t, sql.ErrNoRows, err)
+	})
+
+	t.Run("Migration History Record Is Deleted", func(t *testing.T) {
+		row := db.QueryRow("SELECT name FROM __migration_history WHERE name = 'CreateTable'")
+		var name string
+		err = row.Scan(&name)
+
+		assert.Equal(t, sql.ErrNoRows, err)
+	})
+}
+
+func TestRollback_GivenNoPath_ReturnsError(t *testing.T) {
+	p := &sqlite.SQLite{}
+	err := p.Rollback(context.TODO(), "", "")
+	assert.NotNil(t, err)
+}
+
+func TestRollback_GivenMigrationWithInvalidSQL_ReturnsError(t *testing.T) {
+	p := &sqlite.SQLite{
+		ConnectionString: testDatabase(t),
+		HistoryTableName: "__migration_history",
+	}
+	err := p.Rollback(context.TODO(), "CreateTable", `DROP TABLE test_rollback'`) // invalid sql
+	assert.NotNil(t, err)
+}
+
+func TestApplyAndRollback_GivenExampleMigrations_RunsFullCycle(t *testing.T) {
+	path := testDatabase(t)
+	dir := t.TempDir()
+
+	files := map[string]string{
+		"create_table.up.sql":   "CREATE TABLE people (name VARCHAR(255) NOT NULL);",
+		"create_table.down.sql": "DROP TABLE people;",
+		"add_record.up.sql":     "INSERT INTO people (name) VALUES ('Reece');",
+		"add_record.down.sql":   "DELETE FROM people WHERE name = 'Reece';",
+	}
+	for name, content := range files {
+		err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644)
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	cm := []*migrations.Migration{
+		{Name: "CreateTable", UpFile: "create_table.up.sql", DownFile: "create_table.down.sql"},
+		{Name: "AddRecord", UpFile: "add_record.up.sql", DownFile: "add_record.down.sql"},
+	}
+	p, err := sqlite.New(migrations.ConfigMap{"path": path})
+	assert.NoError(t, err)
+
+	fr := migrations.NewFileReader(dir)
+	ctx := context.TODO()
+
+	err = migrations.Apply(ctx, cm, p, fr, "")
+	assert.NoError(t, err)
+
+	statuses, err := migrations.Status(ctx, cm, p)
+	assert.NoError(t, err)
+	assert.Equal(t, migrations.StateApplied, statuses[0].State)
+	assert.Equal(t, migrations.StateApplied, statuses[1].State)
+
+	drifts, err := migrations.Verify(ctx, cm, p, fr)
+	assert.NoError(t, err)
+	assert.Empty(t, drifts)
+
+	err = migrations.Rollback(ctx, cm, p, fr, "")
+	assert.NoError(t, err)
+
+	am, err := p.GetAppliedMigrations(ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, am)
+}
+
+func TestMarkApplied_GivenMigration_RecordsMigrationWithoutApplyingIt(t *testing.T) {
+	path := testDatabase(t)
+
+	p := &sqlite.SQLite{
+		ConnectionString: path,
+		HistoryTableName: "__migration_history",
+	}
+	err := p.MarkApplied(context.TODO(), "CreateTable", "CREATE TABLE TestMarkApplied (name VARCHAR(255) NOT NULL);", "")
+	assert.NoError(t, err)
+
+	am, err := p.GetAppliedMigrations(context.TODO())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(am))
+	assert.Equal(t, "CreateTable", am[0].Name)
+	assert.Equal(t, migrations.Checksum("CREATE TABLE TestMarkApplied (name VARCHAR(255) NOT NULL);"), am[0].Checksum)
+
+	var count int
+	err = openDB(path).QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE name = 'TestMarkApplied'").Scan(&count)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, count)
+}
+
+func TestMarkApplied_GivenNote_StoresNoteWithRecord(t *testing.T) {
+	path := testDatabase(t)
+
+	p := &sqlite.SQLite{
+		ConnectionString: path,
+		HistoryTableName: "__migration_history",
+	}
+	err := p.MarkApplied(context.TODO(), "CreateTable", "CREATE TABLE TestMarkApplied (name VARCHAR(255) NOT NULL);", "applied by hand")
+	assert.NoError(t, err)
+
+	var note sql.NullString
+	err = openDB(path).QueryRow("SELECT note FROM __migration_history WHERE name = 'CreateTable'").Scan(&note)
+	assert.NoError(t, err)
+	assert.Equal(t, sql.NullString{String: "applied by hand", Valid: true}, note)
+}
+
+func TestMarkApplied_WhereHistoryTableHasNoNoteColumn_AddsColumn(t *testing.T) {
+	path := testDatabase(t)
+
+	db := openDB(path)
+	defer db.Close()
+
+	execute(db, `CREATE TABLE __migration_history (
+			id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+			name VARCHAR(255) NOT NULL,
+			date_applied DATETIME NOT NULL,
+			checksum VARCHAR(64) NULL
+		);`)
+
+	p := &sqlite.SQLite{
+		ConnectionString: path,
+		HistoryTableName: "__migration_history",
+	}
+	err := p.MarkApplied(context.TODO(), "CreateTable", "CREATE TABLE TestMarkApplied (name VARCHAR(255) NOT NULL);", "applied by hand")
+	assert.NoError(t, err)
+
+	var note sql.NullString
+	err = db.QueryRow("SELECT note FROM __migration_history WHERE name = 'CreateTable'").Scan(&note)
+	assert.NoError(t, err)
+	assert.Equal(t, "applied by hand", note.String)
+}
+
+func TestMarkUnapplied_GivenAppliedMigration_RemovesRecordWithoutRollingBack(t *testing.T) {
+	path := testDatabase(t)
+
+	p := &sqlite.SQLite{
+		ConnectionString: path,
+		HistoryTableName: "__migration_history",
+	}
+	err := p.Apply(context.TODO(), "CreateTable", "CREATE TABLE TestMarkUnapplied (name VARCHAR(255) NOT NULL);")
+	assert.NoError(t, err)
+
+	err = p.MarkUnapplied(context.TODO(), "CreateTable")
+	assert.NoError(t, err)
+
+	am, err := p.GetAppliedMigrations(context.TODO())
+	assert.NoError(t, err)
+	assert.Empty(t, am)
+
+	var count int
+	err = openDB(path).QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE name = 'TestMarkUnapplied'").Scan(&count)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+}
+
+func TestClose_WhereProviderIsUsedAgain_ReopensConnection(t *testing.T) {
+	p := &sqlite.SQLite{
+		ConnectionString: testDatabase(t),
+		HistoryTableName: "__migration_history",
+		MaxOpenConns:     1,
+	}
+
+	err := p.Apply(context.TODO(), "CreateTable", "CREATE TABLE TestClose (name VARCHAR(255) NOT NULL);")
+	assert.NoError(t, err)
+
+	// with a single connection, leaking one would cause this to block.
+	am, err := p.GetAppliedMigrations(context.TODO())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(am))
+
+	assert.NoError(t, p.Close())
+	assert.NoError(t, p.Close())
+
+	am, err = p.GetAppliedMigrations(context.TODO())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(am))
+	assert.NoError(t, p.Close())
+}
+
+func TestTemplate(t *testing.T) {
+	p := &sqlite.SQLite{}
+	assert.Contains(t, p.Template("CreateTable", migrations.Up), "-- CreateTable (up)\n")
+	assert.Contains(t, p.Template("CreateTable", migrations.Down), "-- CreateTable (down)\n")
+}
@@ -0,0 +1,75 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+)
+
+// Redo rolls back the last n applied migrations, by the configured order, then
+// re-applies them, reading the migration files afresh. This is intended for iterating on a migration during
+// development. Only the migrations rolled back are re-applied, so any pending
+// migrations configured between them are left pending. The Steps option is ignored.
+//
+// If the migrations are rolled back, but fail to be re-applied, they are left rolled
+// back, unless the Transactional option is enabled, in which case only those
+// migrations re-applied in this run are rolled back. Likewise, if a migration fails
+// to be rolled back, those rolled back in this run are only re-applied if the
+// Transactional option is enabled.
+//
+// If the VerifyChecksums option is enabled, the applied migrations which aren't
+// being redone are verified before any are rolled back; a *DriftError is returned.
+//
+// If p implements Locker, the migration lock is held for the whole of the redo.
+func Redo(ctx context.Context, cm []*Migration, p Provider, fr FileReader, n int, opts ...Option) error {
+	if n < 1 {
+		return fmt.Errorf("the number of migrations to redo must be at least 1")
+	}
+
+	return withLock(ctx, p, opts, func(ctx context.Context, o *options, am []*Migration) error {
+		var applied []*Migration
+		for _, m := range cm {
+			if isApplied(am, m.Name) {
+				applied = append(applied, m)
+			}
+		}
+
+		if n > len(applied) {
+			return fmt.Errorf("cannot redo %d migrations, as only %d are applied", n, len(applied))
+		}
+
+		redo := applied[len(applied)-n:]
+
+		if o.verifyChecksums {
+			drifts, err := verify(applied[:len(applied)-n], am, fr)
+			if err != nil {
+				return err
+			}
+
+			if len(drifts) > 0 {
+				return &DriftError{Drifts: drifts}
+			}
+		}
+
+		var rolledBack []*Migration
+		for i := len(redo) - 1; i >= 0; i-- {
+			err := runMigration(ctx, redo[i], Down, p, fr, false)
+			if err != nil {
+				return o.compensateRollback(ctx, rolledBack, p, fr, err)
+			}
+
+			rolledBack = append(rolledBack, redo[i])
+		}
+
+		var reapplied []*Migration
+		for _, m := range redo {
+			err := runMigration(ctx, m, Up, p, fr, false)
+			if err != nil {
+				return o.compensateApply(ctx, reapplied, p, fr, err)
+			}
+
+			reapplied = append(reapplied, m)
+		}
+
+		return nil
+	})
+}
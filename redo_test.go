@@ -0,0 +1,137 @@
+package migrations_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/reecerussell/migrations"
+	"github.com/reecerussell/migrations/mock"
+)
+
+func TestRedo_GivenSteps_RollsBackAndReappliesLastMigrations(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	testCtx := context.Background()
+	cm := []*migrations.Migration{
+		{Name: "First", UpFile: "FirstUp", DownFile: "FirstDown"},
+		{Name: "Second", UpFile: "SecondUp", DownFile: "SecondDown"},
+		{Name: "Third", UpFile: "ThirdUp", DownFile: "ThirdDown"},
+		{Name: "Fourth", UpFile: "FourthUp", DownFile: "FourthDown"},
+	}
+	applied := []*migrations.Migration{{Name: "First"}, {Name: "Second"}, {Name: "Third"}}
+
+	mockProvider := mock.NewMockProvider(ctrl)
+	mockFileReader := mock.NewMockFileReader(ctrl)
+	gomock.InOrder(
+		mockProvider.EXPECT().GetAppliedMigrations(testCtx).Return(applied, nil),
+		mockFileReader.EXPECT().Read("ThirdDown").Return("ThirdDownContent", nil),
+		mockProvider.EXPECT().Rollback(testCtx, "Third", "ThirdDownContent").Return(nil),
+		mockFileReader.EXPECT().Read("SecondDown").Return("SecondDownContent", nil),
+		mockProvider.EXPECT().Rollback(testCtx, "Second", "SecondDownContent").Return(nil),
+		mockFileReader.EXPECT().Read("SecondUp").Return("SecondContent", nil),
+		mockProvider.EXPECT().Apply(testCtx, "Second", "SecondContent").Return(nil),
+		mockFileReader.EXPECT().Read("ThirdUp").Return("ThirdContent", nil),
+		mockProvider.EXPECT().Apply(testCtx, "Third", "ThirdContent").Return(nil),
+	)
+
+	err := migrations.Redo(testCtx, cm, mockProvider, mockFileReader, 2)
+	assert.NoError(t, err)
+}
+
+func TestRedo_HavingPendingMigrationBetweenApplied_LeavesItPending(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	testCtx := context.Background()
+	cm := []*migrations.Migration{
+		{Name: "One", UpFile: "OneUp", DownFile: "OneDown"},
+		{Name: "Two", UpFile: "TwoUp", DownFile: "TwoDown"},
+		{Name: "Three", UpFile: "ThreeUp", DownFile: "ThreeDown"},
+	}
+	applied := []*migrations.Migration{{Name: "One"}, {Name: "Three"}}
+
+	mockProvider := mock.NewMockProvider(ctrl)
+	mockFileReader := mock.NewMockFileReader(ctrl)
+	gomock.InOrder(
+		mockProvider.EXPECT().GetAppliedMigrations(testCtx).Return(applied, nil),
+		mockFileReader.EXPECT().Read("ThreeDown").Return("ThreeDownContent", nil),
+		mockProvider.EXPECT().Rollback(testCtx, "Three", "ThreeDownContent").Return(nil),
+		mockFileReader.EXPECT().Read("OneDown").Return("OneDownContent", nil),
+		mockProvider.EXPECT().Rollback(testCtx, "One", "OneDownContent").Return(nil),
+		mockFileReader.EXPECT().Read("OneUp").Return("OneContent", nil),
+		mockProvider.EXPECT().Apply(testCtx, "One", "OneContent").Return(nil),
+		mockFileReader.EXPECT().Read("ThreeUp").Return("ThreeContent", nil),
+		mockProvider.EXPECT().Apply(testCtx, "Three", "ThreeContent").Return(nil),
+	)
+
+	err := migrations.Redo(testCtx, cm, mockProvider, mockFileReader, 2)
+	assert.NoError(t, err)
+}
+
+func TestRedo_WithLocker_HoldsLockForWholeRedo(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	testCtx := context.Background()
+	cm := []*migrations.Migration{{Name: "First", UpFile: "FirstUp", DownFile: "FirstDown"}}
+
+	mockProvider := mock.NewMockProvider(ctrl)
+	mockLocker := mock.NewMockLocker(ctrl)
+	mockFileReader := mock.NewMockFileReader(ctrl)
+	gomock.InOrder(
+		mockLocker.EXPECT().Lock(testCtx, migrations.DefaultLockTimeout).Return(nil),
+		mockProvider.EXPECT().GetAppliedMigrations(testCtx).Return(cm, nil),
+		mockFileReader.EXPECT().Read("FirstDown").Return("FirstDownContent", nil),
+		mockProvider.EXPECT().Rollback(testCtx, "First", "FirstDownContent").Return(nil),
+		mockFileReader.EXPECT().Read("FirstUp").Return("FirstContent", nil),
+		mockProvider.EXPECT().Apply(testCtx, "First", "FirstContent").Return(nil),
+		mockLocker.EXPECT().Unlock(testCtx).Return(nil),
+	)
+
+	p := &lockingProvider{mockProvider, mockLocker}
+	err := migrations.Redo(testCtx, cm, p, mockFileReader, 1)
+	assert.NoError(t, err)
+}
+
+func TestRedo_FailsToRollback_ReturnsErrorWithoutReapplying(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	testCtx := context.Background()
+	testError := errors.New("an error occurred")
+	cm := []*migrations.Migration{{Name: "First", UpFile: "FirstUp", DownFile: "FirstDown"}}
+
+	mockProvider := mock.NewMockProvider(ctrl)
+	mockProvider.EXPECT().GetAppliedMigrations(testCtx).Return(cm, nil)
+	mockProvider.EXPECT().Rollback(testCtx, "First", "FirstDownContent").Return(testError)
+
+	mockFileReader := mock.NewMockFileReader(ctrl)
+	mockFileReader.EXPECT().Read("FirstDown").Return("FirstDownContent", nil)
+
+	err := migrations.Redo(testCtx, cm, mockProvider, mockFileReader, 1)
+	assert.Equal(t, testError, err)
+}
+
+func TestRedo_GivenMoreStepsThanApplied_ReturnsError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	testCtx := context.Background()
+	cm := []*migrations.Migration{{Name: "First"}, {Name: "Second"}}
+
+	mockProvider := mock.NewMockProvider(ctrl)
+	mockProvider.EXPECT().GetAppliedMigrations(testCtx).Return(cm[:1], nil)
+
+	err := migrations.Redo(testCtx, cm, mockProvider, nil, 2)
+	assert.EqualError(t, err, "cannot redo 2 migrations, as only 1 are applied")
+}
+
+func TestRedo_GivenZeroSteps_ReturnsError(t *testing.T) {
+	err := migrations.Redo(context.Background(), nil, nil, nil, 0)
+	assert.EqualError(t, err, "the number of migrations to redo must be at least 1")
+}
@@ -6,41 +6,70 @@ import (
 )
 
 // Rollback rolls back all applied migrations, up to the target (if any), using the given provider, p.
-func Rollback(ctx context.Context, cm []*Migration, p Provider, fr FileReader, targetName string) error {
+// If the target doesn't exist, a *NotFoundError is returned, or if it isn't applied, an error
+// is returned, before any migrations are rolled back.
+//
+// If the Transactional option is enabled and a migration fails, each migration
+// rolled back in this run is re-applied, in reverse order, and a *CompensationError is returned.
+//
+// If p implements Locker, the migration lock is held for the duration of the run.
+//
+// Progress is reported to the Observer given by the WithObserver option, if any.
+func Rollback(ctx context.Context, cm []*Migration, p Provider, fr FileReader, targetName string, opts ...Option) error {
+	o, err := newOptions(opts)
+	if err != nil {
+		return err
+	}
+
+	err = resolveTarget(cm, targetName)
+	if err != nil {
+		return err
+	}
+
+	ctx = o.context(ctx)
+
+	unlock, err := acquireLock(ctx, p, o.lockTimeout)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
 	am, err := p.GetAppliedMigrations(ctx)
 	if err != nil {
 		return err
 	}
 
-	for i := len(cm) - 1; i >= 0; i-- {
-		m := cm[i]
+	if targetName != "" && !isApplied(am, targetName) {
+		return fmt.Errorf("migration %s is not applied", targetName)
+	}
 
-		fmt.Printf("Rolling back %s...\t", m.Name)
+	var rolledBack []*Migration
 
-		if !isApplied(am, m.Name) {
-			fmt.Printf("skipping.\n")
-			continue
+	return walk(cm, am, Down, targetName, o.steps, func(m *Migration, pending bool) error {
+		if !pending {
+			skipMigration(ctx, m, Down)
+			return nil
 		}
 
-		content, err := fr.Read(m.DownFile)
+		err := runMigration(ctx, m, Down, p, fr, false)
 		if err != nil {
-			fmt.Printf("\nFailed to read migration file: %s.\n", m.DownFile)
-			return err
+			return o.compensateRollback(ctx, rolledBack, p, fr, err)
 		}
 
-		err = p.Rollback(ctx, m.Name, content)
-		if err != nil {
-			fmt.Printf("\nFailed to rollback migration %s.\n", m.Name)
+		rolledBack = append(rolledBack, m)
 
-			return err
-		}
-
-		fmt.Printf("done.\n")
+		return nil
+	})
+}
 
-		if targetName != "" && targetName == m.Name {
-			return nil
-		}
+// compensateRollback re-applies the migrations rolled back in the current run,
+// if running transactionally, otherwise err is returned as is.
+func (o *options) compensateRollback(ctx context.Context, rolledBack []*Migration, p Provider, fr FileReader, err error) error {
+	if !o.transactional {
+		return err
 	}
 
-	return nil
+	return compensate(rolledBack, err, func(m *Migration) error {
+		return runMigration(ctx, m, Up, p, fr, true)
+	})
 }
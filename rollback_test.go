@@ -114,6 +114,35 @@ func TestRollback_RollbackTargetMigration_SkipsFurtherMigrations(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestRollback_GivenUnknownTarget_ReturnsNotFoundError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	testMigrations := []*migrations.Migration{{Name: "AddUsersTable"}}
+
+	mockProvider := mock.NewMockProvider(ctrl)
+
+	err := migrations.Rollback(context.Background(), testMigrations, mockProvider, nil, "addusertable")
+	assert.EqualError(t, err, "migration addusertable does not exist, did you mean AddUsersTable?")
+}
+
+func TestRollback_GivenUnappliedTarget_ReturnsError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	testCtx := context.Background()
+	testMigrations := []*migrations.Migration{
+		{Name: "One"},
+		{Name: "Two"},
+	}
+
+	mockProvider := mock.NewMockProvider(ctrl)
+	mockProvider.EXPECT().GetAppliedMigrations(testCtx).Return([]*migrations.Migration{{Name: "One"}}, nil)
+
+	err := migrations.Rollback(testCtx, testMigrations, mockProvider, nil, "Two")
+	assert.EqualError(t, err, "migration Two is not applied")
+}
+
 func TestRollback_FailsToGetAppliedMigrations_ReturnsError(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -127,3 +156,98 @@ func TestRollback_FailsToGetAppliedMigrations_ReturnsError(t *testing.T) {
 	err := migrations.Rollback(testCtx, nil, mockProvider, nil, "")
 	assert.Equal(t, testError, err)
 }
+
+func TestRollback_TransactionalWhereRollbackFails_ReappliesRolledBackMigrations(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	testCtx := context.Background()
+	testMigrations := []*migrations.Migration{
+		{Name: "One", UpFile: "One.up", DownFile: "One.down"},
+		{Name: "Two", UpFile: "Two.up", DownFile: "Two.down"},
+		{Name: "Three", UpFile: "Three.up", DownFile: "Three.down"},
+	}
+	testError := errors.New("an error occurred")
+
+	mockProvider := mock.NewMockProvider(ctrl)
+	mockFileReader := mock.NewMockFileReader(ctrl)
+
+	gomock.InOrder(
+		mockProvider.EXPECT().GetAppliedMigrations(testCtx).Return(testMigrations, nil),
+		mockFileReader.EXPECT().Read("Three.down").Return("Three Down", nil),
+		mockProvider.EXPECT().Rollback(testCtx, "Three", "Three Down").Return(nil),
+		mockFileReader.EXPECT().Read("Two.down").Return("Two Down", nil),
+		mockProvider.EXPECT().Rollback(testCtx, "Two", "Two Down").Return(nil),
+		mockFileReader.EXPECT().Read("One.down").Return("One Down", nil),
+		mockProvider.EXPECT().Rollback(testCtx, "One", "One Down").Return(testError),
+		mockFileReader.EXPECT().Read("Two.up").Return("Two Up", nil),
+		mockProvider.EXPECT().Apply(testCtx, "Two", "Two Up").Return(nil),
+		mockFileReader.EXPECT().Read("Three.up").Return("Three Up", nil),
+		mockProvider.EXPECT().Apply(testCtx, "Three", "Three Up").Return(nil),
+	)
+
+	err := migrations.Rollback(testCtx, testMigrations, mockProvider, mockFileReader, "", migrations.Transactional(true))
+	assert.True(t, errors.Is(err, testError))
+
+	var ce *migrations.CompensationError
+	assert.True(t, errors.As(err, &ce))
+	assert.Equal(t, []string{"Two", "Three"}, ce.Compensated)
+	assert.Empty(t, ce.Failed)
+}
+
+func TestRollback_TransactionalWhereCompensationFails_ReportsFailure(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	testCtx := context.Background()
+	testMigrations := []*migrations.Migration{
+		{Name: "One", UpFile: "One.up", DownFile: "One.down"},
+		{Name: "Two", UpFile: "Two.up", DownFile: "Two.down"},
+	}
+	testError := errors.New("an error occurred")
+	testCompensationError := errors.New("another error occurred")
+
+	mockProvider := mock.NewMockProvider(ctrl)
+	mockProvider.EXPECT().GetAppliedMigrations(testCtx).Return(testMigrations, nil)
+	mockProvider.EXPECT().Rollback(testCtx, "Two", "Two Down").Return(nil)
+	mockProvider.EXPECT().Rollback(testCtx, "One", "One Down").Return(testError)
+	mockProvider.EXPECT().Apply(testCtx, "Two", "Two Up").Return(testCompensationError)
+
+	mockFileReader := mock.NewMockFileReader(ctrl)
+	mockFileReader.EXPECT().Read("Two.down").Return("Two Down", nil)
+	mockFileReader.EXPECT().Read("One.down").Return("One Down", nil)
+	mockFileReader.EXPECT().Read("Two.up").Return("Two Up", nil)
+
+	err := migrations.Rollback(testCtx, testMigrations, mockProvider, mockFileReader, "", migrations.Transactional(true))
+
+	var ce *migrations.CompensationError
+	assert.True(t, errors.As(err, &ce))
+	assert.Equal(t, testError, ce.Err)
+	assert.Empty(t, ce.Compensated)
+	assert.Equal(t, []*migrations.CompensationFailure{{Name: "Two", Err: testCompensationError}}, ce.Failed)
+}
+
+func TestRollback_GivenSteps_RollsBackNumberOfAppliedMigrations(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	testCtx := context.Background()
+	testMigrations := []*migrations.Migration{
+		{Name: "One"},
+		{Name: "Two", DownFile: "TwoDown"},
+		{Name: "Three", DownFile: "ThreeDown"},
+		{Name: "Four"},
+	}
+
+	mockProvider := mock.NewMockProvider(ctrl)
+	mockProvider.EXPECT().GetAppliedMigrations(testCtx).Return(testMigrations[:3], nil)
+	mockProvider.EXPECT().Rollback(testCtx, "Three", "ThreeContent").Return(nil)
+	mockProvider.EXPECT().Rollback(testCtx, "Two", "TwoContent").Return(nil)
+
+	mockFileReader := mock.NewMockFileReader(ctrl)
+	mockFileReader.EXPECT().Read("ThreeDown").Return("ThreeContent", nil)
+	mockFileReader.EXPECT().Read("TwoDown").Return("TwoContent", nil)
+
+	err := migrations.Rollback(testCtx, testMigrations, mockProvider, mockFileReader, "", migrations.Steps(2))
+	assert.NoError(t, err)
+}
@@ -0,0 +1,75 @@
+package migrations
+
+import (
+	"context"
+	"time"
+)
+
+// runMigration runs the migration, m, in the direction, d, using the provider, p,
+// notifying the Observer held by ctx of its progress. Compensating should be true
+// if m is being run to compensate for a failed migration.
+func runMigration(ctx context.Context, m *Migration, d Direction, p Provider, fr FileReader, compensating bool) error {
+	obs := ObserverFromContext(ctx)
+	obs.Observe(&Event{
+		Type:         EventMigrationStarted,
+		Time:         time.Now(),
+		Direction:    d,
+		Migration:    m.Name,
+		Compensating: compensating,
+	})
+
+	start := time.Now()
+	err := execMigration(ctx, m, d, p, fr)
+
+	e := &Event{
+		Type:         EventMigrationSucceeded,
+		Time:         time.Now(),
+		Direction:    d,
+		Migration:    m.Name,
+		Duration:     time.Since(start),
+		Err:          err,
+		Compensating: compensating,
+	}
+	if err != nil {
+		e.Type = EventMigrationFailed
+	}
+	obs.Observe(e)
+
+	return err
+}
+
+// execMigration reads the file of the migration, m, for the direction, d,
+// then applies or rolls it back using the provider, p. If m isn't run in a
+// transaction, the provider is told so by ctx.
+func execMigration(ctx context.Context, m *Migration, d Direction, p Provider, fr FileReader) error {
+	if !m.InTransaction() {
+		ctx = ContextWithoutTransaction(ctx)
+	}
+
+	if d == Down {
+		content, err := fr.Read(m.DownFile)
+		if err != nil {
+			return err
+		}
+
+		return p.Rollback(ctx, m.Name, content)
+	}
+
+	content, err := fr.Read(m.UpFile)
+	if err != nil {
+		return err
+	}
+
+	return p.Apply(ctx, m.Name, content)
+}
+
+// skipMigration notifies the Observer held by ctx that
+// the migration, m, is being skipped in the direction, d.
+func skipMigration(ctx context.Context, m *Migration, d Direction) {
+	ObserverFromContext(ctx).Observe(&Event{
+		Type:      EventMigrationSkipped,
+		Time:      time.Now(),
+		Direction: d,
+		Migration: m.Name,
+	})
+}
@@ -51,7 +51,7 @@ build_amd64() {
     log_verbose "> Output: $output"
     log_info "---\n"
 
-    main=cmd/main.go
+    main=./cmd
     log_info "Building $main..."
     GOOS=$os GOARCH=amd64 CGO_ENABLED=0 go build -o $output $main &> out.txt
     exit_if_error $? "An error occurred while building" "$(cat out.txt && rm out.txt)"
@@ -0,0 +1,81 @@
+package migrations
+
+import (
+	"context"
+	"time"
+)
+
+// MigrationState describes the state of a migration against a database.
+type MigrationState string
+
+// Possible values of MigrationState.
+const (
+	// StateApplied indicates the migration has been applied.
+	StateApplied MigrationState = "applied"
+
+	// StatePending indicates the migration has not yet been applied.
+	StatePending MigrationState = "pending"
+
+	// StateUnknown indicates the migration has been applied, but
+	// is missing from the migrations config.
+	StateUnknown MigrationState = "unknown"
+)
+
+// MigrationStatus represents the state of a single migration.
+type MigrationStatus struct {
+	Name        string         `json:"name"`
+	State       MigrationState `json:"state"`
+	DateApplied *time.Time     `json:"dateApplied,omitempty"`
+}
+
+// Status returns the state of each of the configured migrations, cm, using the
+// given provider, p. Migrations which have been applied, but are missing from cm,
+// are appended to the result, in the order the provider returned them.
+func Status(ctx context.Context, cm []*Migration, p Provider) ([]*MigrationStatus, error) {
+	am, err := p.GetAppliedMigrations(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]*MigrationStatus, 0, len(cm))
+
+	for _, m := range cm {
+		s := &MigrationStatus{
+			Name:  m.Name,
+			State: StatePending,
+		}
+
+		if a := findApplied(am, m.Name); a != nil {
+			dateApplied := a.DateApplied
+			s.State = StateApplied
+			s.DateApplied = &dateApplied
+		}
+
+		statuses = append(statuses, s)
+	}
+
+	for _, a := range am {
+		if findApplied(cm, a.Name) != nil {
+			continue
+		}
+
+		dateApplied := a.DateApplied
+		statuses = append(statuses, &MigrationStatus{
+			Name:        a.Name,
+			State:       StateUnknown,
+			DateApplied: &dateApplied,
+		})
+	}
+
+	return statuses, nil
+}
+
+func findApplied(applied []*Migration, name string) *Migration {
+	for _, m := range applied {
+		if m.Name == name {
+			return m
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,56 @@
+package migrations_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/reecerussell/migrations"
+	"github.com/reecerussell/migrations/mock"
+)
+
+func TestStatus_GivenMigrations_ReturnsStateOfEach(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	testCtx := context.Background()
+	testDate := time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)
+	testMigrations := []*migrations.Migration{
+		{Name: "One"},
+		{Name: "Two"},
+	}
+	testAppliedMigrations := []*migrations.Migration{
+		{Name: "One", DateApplied: testDate},
+		{Name: "Removed", DateApplied: testDate},
+	}
+
+	mockProvider := mock.NewMockProvider(ctrl)
+	mockProvider.EXPECT().GetAppliedMigrations(testCtx).Return(testAppliedMigrations, nil)
+
+	statuses, err := migrations.Status(testCtx, testMigrations, mockProvider)
+	assert.NoError(t, err)
+	assert.Equal(t, []*migrations.MigrationStatus{
+		{Name: "One", State: migrations.StateApplied, DateApplied: &testDate},
+		{Name: "Two", State: migrations.StatePending},
+		{Name: "Removed", State: migrations.StateUnknown, DateApplied: &testDate},
+	}, statuses)
+}
+
+func TestStatus_FailsToGetAppliedMigrations_ReturnsError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	testCtx := context.Background()
+	testError := errors.New("an error occurred")
+
+	mockProvider := mock.NewMockProvider(ctrl)
+	mockProvider.EXPECT().GetAppliedMigrations(testCtx).Return(nil, testError)
+
+	statuses, err := migrations.Status(testCtx, nil, mockProvider)
+	assert.Nil(t, statuses)
+	assert.Equal(t, testError, err)
+}
@@ -0,0 +1,107 @@
+package migrations
+
+import (
+	"fmt"
+	"strings"
+)
+
+// NotFoundError is returned if a migration, such as a target, doesn't
+// exist in the configured migrations.
+type NotFoundError struct {
+	Name string
+
+	// Suggestion is the name of the configured migration most similar
+	// to Name, if any is similar enough to be a likely typo.
+	Suggestion string
+}
+
+func (e *NotFoundError) Error() string {
+	if e.Suggestion == "" {
+		return fmt.Sprintf("migration %s does not exist", e.Name)
+	}
+
+	return fmt.Sprintf("migration %s does not exist, did you mean %s?", e.Name, e.Suggestion)
+}
+
+// findConfigured returns the configured migration with the given name,
+// or a *NotFoundError, suggesting a similarly named migration, if any.
+func findConfigured(cm []*Migration, name string) (*Migration, error) {
+	if m := findApplied(cm, name); m != nil {
+		return m, nil
+	}
+
+	return nil, &NotFoundError{Name: name, Suggestion: suggest(cm, name)}
+}
+
+// resolveTarget returns an error if the target isn't empty, and
+// doesn't exist in the configured migrations, cm.
+func resolveTarget(cm []*Migration, targetName string) error {
+	if targetName == "" {
+		return nil
+	}
+
+	_, err := findConfigured(cm, targetName)
+
+	return err
+}
+
+// suggest returns the name of the migration most similar to name, ignoring
+// case, if it's within a few edits of it, otherwise an empty string.
+func suggest(cm []*Migration, name string) string {
+	maxDistance := len(name) / 3
+	if maxDistance < 2 {
+		maxDistance = 2
+	}
+
+	suggestion := ""
+	best := maxDistance + 1
+
+	for _, m := range cm {
+		d := editDistance(strings.ToLower(name), strings.ToLower(m.Name))
+		if d < best {
+			suggestion, best = m.Name, d
+		}
+	}
+
+	return suggestion
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+
+			curr[j] = min3(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+
+		prev, curr = curr, prev
+	}
+
+	return prev[len(rb)]
+}
+
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+
+	if c < a {
+		a = c
+	}
+
+	return a
+}
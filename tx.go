@@ -0,0 +1,80 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// TxPhase is a step of the transaction a provider runs a migration in.
+type TxPhase string
+
+// Possible values of TxPhase.
+const (
+	// TxBegin is the start of the transaction, or, for a migration run without
+	// one, acquiring the connection it is run on.
+	TxBegin TxPhase = "begin"
+
+	// TxExec is the execution of the migration's content.
+	TxExec TxPhase = "exec"
+
+	// TxHistory is the update of the migration history table.
+	TxHistory TxPhase = "history"
+
+	// TxCommit is the commit of the transaction.
+	TxCommit TxPhase = "commit"
+)
+
+// TxError is returned by a Provider if a phase of the transaction
+// a migration is applied, or rolled back, in fails. In particular,
+// if the commit fails, none of the migration's changes were persisted.
+// Migrations not run in a transaction only have exec and history phases.
+type TxError struct {
+	Migration string
+	Phase     TxPhase
+	Err       error
+}
+
+func (e *TxError) Error() string {
+	return fmt.Sprintf("migration %s failed at %s: %v", e.Migration, e.Phase, e.Err)
+}
+
+func (e *TxError) Unwrap() error {
+	return e.Err
+}
+
+type noTransactionKey struct{}
+
+// ContextWithoutTransaction returns a copy of ctx, telling providers not to run
+// the migration in a transaction. This is how Apply and Rollback pass on a
+// migration's Transaction field, as providers only receive its name and content.
+func ContextWithoutTransaction(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noTransactionKey{}, true)
+}
+
+// TransactionFromContext determines whether the migration being applied, or rolled
+// back, should be run in a transaction, which it should unless ctx says otherwise.
+// Providers which don't use a transaction should update the migration history only
+// once the migration's statements have succeeded.
+func TransactionFromContext(ctx context.Context) bool {
+	noTransaction, _ := ctx.Value(noTransactionKey{}).(bool)
+
+	return !noTransaction
+}
+
+// ParseIsolationLevel returns the isolation level with the given name, such as
+// "read committed" or "SERIALIZABLE". Case, spaces, hyphens and underscores are
+// ignored, so "ReadCommitted" and "read_committed" are also accepted.
+func ParseIsolationLevel(name string) (sql.IsolationLevel, error) {
+	normalize := strings.NewReplacer(" ", "", "-", "", "_", "")
+	want := strings.ToLower(normalize.Replace(name))
+
+	for level := sql.LevelDefault; level <= sql.LevelLinearizable; level++ {
+		if strings.ToLower(normalize.Replace(level.String())) == want {
+			return level, nil
+		}
+	}
+
+	return sql.LevelDefault, fmt.Errorf("unknown isolation level: %s", name)
+}
@@ -0,0 +1,41 @@
+package migrations_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/reecerussell/migrations"
+)
+
+func TestTransactionFromContext(t *testing.T) {
+	ctx := context.Background()
+	assert.True(t, migrations.TransactionFromContext(ctx))
+
+	ctx = migrations.ContextWithoutTransaction(ctx)
+	assert.False(t, migrations.TransactionFromContext(ctx))
+}
+
+func TestParseIsolationLevel(t *testing.T) {
+	tests := map[string]sql.IsolationLevel{
+		"read uncommitted": sql.LevelReadUncommitted,
+		"READ COMMITTED":   sql.LevelReadCommitted,
+		"RepeatableRead":   sql.LevelRepeatableRead,
+		"snapshot":         sql.LevelSnapshot,
+		"serializable":     sql.LevelSerializable,
+		"read_committed":   sql.LevelReadCommitted,
+	}
+
+	for name, want := range tests {
+		t.Run(name, func(t *testing.T) {
+			level, err := migrations.ParseIsolationLevel(name)
+			assert.NoError(t, err)
+			assert.Equal(t, want, level)
+		})
+	}
+
+	_, err := migrations.ParseIsolationLevel("eventual")
+	assert.EqualError(t, err, "unknown isolation level: eventual")
+}
@@ -0,0 +1,94 @@
+package migrations
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ValidationError is returned by Config.Validate, holding
+// every problem found with the config.
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "invalid config: " + strings.Join(e.Problems, "; ")
+}
+
+// Validate checks the config for problems which would otherwise only surface
+// part way through applying migrations: a missing or unknown provider, given
+// the names of the registered providers, duplicate migration names, migrations
+// missing a name, up or down file, and files which don't exist, or can't be read,
+// using fr. All problems are returned together, in a *ValidationError.
+func (c *Config) Validate(fr FileReader, providerNames []string) error {
+	var problems []string
+	addProblem := func(format string, args ...interface{}) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	switch {
+	case c.Provider == "":
+		addProblem("provider is missing")
+	case !contains(providerNames, c.Provider):
+		addProblem("unknown provider '%s', expected one of: %s", c.Provider, strings.Join(providerNames, ", "))
+	}
+
+	seen := make(map[string]bool, len(c.Migrations))
+
+	for i, m := range c.Migrations {
+		// Migrations are referred to by position, if they have no name.
+		ref := fmt.Sprintf("migration %d", i+1)
+
+		if m.Name == "" {
+			addProblem("%s: name is missing", ref)
+		} else {
+			ref = "migration " + m.Name
+
+			if seen[m.Name] {
+				addProblem("%s: name is used by more than one migration", ref)
+			}
+
+			seen[m.Name] = true
+		}
+
+		files := []struct {
+			direction Direction
+			filename  string
+		}{
+			{Up, m.UpFile},
+			{Down, m.DownFile},
+		}
+
+		for _, f := range files {
+			if f.filename == "" {
+				addProblem("%s: %s file is missing", ref, f.direction)
+				continue
+			}
+
+			_, err := fr.Read(f.filename)
+			switch {
+			case os.IsNotExist(err):
+				addProblem("%s: %s file %s does not exist", ref, f.direction, f.filename)
+			case err != nil:
+				addProblem("%s: %s file %s could not be read: %v", ref, f.direction, f.filename, err)
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+
+	return nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
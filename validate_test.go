@@ -0,0 +1,73 @@
+package migrations_test
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/reecerussell/migrations"
+	"github.com/reecerussell/migrations/mock"
+)
+
+func TestConfigValidate_GivenValidConfig_ReturnsNoError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	config := &migrations.Config{
+		Provider: "mssql",
+		Migrations: []*migrations.Migration{
+			{Name: "One", UpFile: "one.up.sql", DownFile: "one.down.sql"},
+		},
+	}
+
+	mockFileReader := mock.NewMockFileReader(ctrl)
+	mockFileReader.EXPECT().Read("one.up.sql").Return("", nil)
+	mockFileReader.EXPECT().Read("one.down.sql").Return("", nil)
+
+	err := config.Validate(mockFileReader, []string{"mssql"})
+	assert.NoError(t, err)
+}
+
+func TestConfigValidate_GivenInvalidConfig_ReturnsAllProblems(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	config := &migrations.Config{
+		Provider: "oracle",
+		Migrations: []*migrations.Migration{
+			{Name: "One", UpFile: "one.up.sql", DownFile: "one.down.sql"},
+			{Name: "One", UpFile: "two.up.sql"},
+			{DownFile: "three.down.sql"},
+		},
+	}
+
+	mockFileReader := mock.NewMockFileReader(ctrl)
+	mockFileReader.EXPECT().Read("one.up.sql").Return("", nil)
+	mockFileReader.EXPECT().Read("one.down.sql").Return("", os.ErrNotExist)
+	mockFileReader.EXPECT().Read("two.up.sql").Return("", errors.New("permission denied"))
+	mockFileReader.EXPECT().Read("three.down.sql").Return("", nil)
+
+	err := config.Validate(mockFileReader, []string{"mssql", "mysql"})
+
+	var validationErr *migrations.ValidationError
+	assert.True(t, errors.As(err, &validationErr))
+	assert.Equal(t, []string{
+		"unknown provider 'oracle', expected one of: mssql, mysql",
+		"migration One: down file one.down.sql does not exist",
+		"migration One: name is used by more than one migration",
+		"migration One: up file two.up.sql could not be read: permission denied",
+		"migration One: down file is missing",
+		"migration 3: name is missing",
+		"migration 3: up file is missing",
+	}, validationErr.Problems)
+}
+
+func TestConfigValidate_GivenMissingProvider_ReturnsError(t *testing.T) {
+	config := &migrations.Config{}
+
+	err := config.Validate(nil, []string{"mssql"})
+	assert.EqualError(t, err, "invalid config: provider is missing")
+}